@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/Nerzal/swag"
 	"github.com/Nerzal/swag/gen"
@@ -23,6 +24,39 @@ const (
 	parseInternalFlag    = "parseInternal"
 	generatedTimeFlag    = "generatedTime"
 	parseDepthFlag       = "parseDepth"
+	stripInternalFlag    = "stripInternal"
+	commentSourcesFlag   = "commentSources"
+	fakerTagFlag         = "fakerTag"
+	parseUnexportedFlag  = "parseUnexportedFields"
+	dryRunFlag           = "dryRun"
+	autoCreateTagsFlag   = "autoCreateTags"
+	instanceNameFlag     = "instanceName"
+	openAPI3Flag         = "openapi3"
+	openAPI31Flag        = "openapi31"
+	parseFuncLocalFlag   = "parseFuncLocalTypes"
+	tagsFlag             = "tags"
+	flattenAllOfFlag     = "flattenAllOf"
+	enumRefsFlag         = "enumRefs"
+	cacheDirFlag         = "cacheDir"
+	noCacheFlag          = "noCache"
+	goosFlag             = "goos"
+	goarchFlag           = "goarch"
+	dependencyPrefixFlag = "dependencyPrefixes"
+	embeddedModeFlag     = "embeddedMode"
+	routerPrefixTrimFlag = "routerPrefixTrim"
+	summaryFromFuncFlag  = "summaryFromFuncName"
+	instancesFlag        = "instances"
+	treatAsStringFlag    = "treatAsString"
+	nameInlineRespFlag   = "nameInlineResponses"
+	int64AsStringFlag    = "int64AsString"
+	jsonNumberFlag       = "jsonNumberAsString"
+	nullableOmitFlag     = "nullableOmitEmpty"
+	omitEmptyFlag        = "omitEmptyExtension"
+	readOnlyFlag         = "readOnlyPatterns"
+	writeOnlyFlag        = "writeOnlyPatterns"
+	strictFieldTypesFlag = "strictFieldTypes"
+	validateTagNameFlag  = "validateTagName"
+	diagnosticsFlag      = "diagnostics"
 )
 
 var initFlags = []cli.Flag{
@@ -40,7 +74,7 @@ var initFlags = []cli.Flag{
 	},
 	&cli.StringFlag{
 		Name:  excludeFlag,
-		Usage: "Exclude directories and files when searching, comma separated",
+		Usage: "Exclude directories and files when searching, comma separated paths, prefixes or glob patterns relative to the search dir",
 	},
 	&cli.StringFlag{
 		Name:    propertyStrategyFlag,
@@ -60,7 +94,7 @@ var initFlags = []cli.Flag{
 	},
 	&cli.BoolFlag{
 		Name:  parseDependencyFlag,
-		Usage: "Parse go files in outside dependency folder, disabled by default",
+		Usage: "Parse go files in outside dependency folder and in local directories of modules replaced in go.mod, disabled by default",
 	},
 	&cli.StringFlag{
 		Name:    markdownFilesFlag,
@@ -87,6 +121,140 @@ var initFlags = []cli.Flag{
 		Value: 100,
 		Usage: "Dependency parse depth",
 	},
+	&cli.BoolFlag{
+		Name:  stripInternalFlag,
+		Usage: "Remove parameters marked with x-internal(true) from the generated docs, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  commentSourcesFlag,
+		Usage: "Parse annotations from non-Go files matching the glob patterns relative to the search dir, comma separated",
+	},
+	&cli.StringFlag{
+		Name:  fakerTagFlag,
+		Usage: "Struct tag like faker:\"email\" used to generate example values for string fields, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  parseUnexportedFlag,
+		Usage: "Parse unexported fields with an explicit json or swaggertype tag, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  dryRunFlag,
+		Usage: "Print a summary of the generated docs without writing any files, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  autoCreateTagsFlag,
+		Usage: "Add tags used by operations to the root tags if they aren't declared, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  instanceNameFlag,
+		Usage: "Name the docs are registered under, like v2, to generate several API versions into their own output directories",
+	},
+	&cli.BoolFlag{
+		Name:  openAPI3Flag,
+		Usage: "Generate OpenAPI 3.0 docs instead of Swagger 2.0, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  openAPI31Flag,
+		Usage: "Generate OpenAPI 3.1 docs instead of Swagger 2.0, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  parseFuncLocalFlag,
+		Usage: "Parse types declared in the function of an operation, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  tagsFlag,
+		Usage: "Build tags satisfied by the parsed files besides GOOS and GOARCH, comma separated",
+	},
+	&cli.StringFlag{
+		Name:  goosFlag,
+		Usage: "Target operating system whose files, like foo_windows.go, are parsed, the host's by default",
+	},
+	&cli.StringFlag{
+		Name:  goarchFlag,
+		Usage: "Target architecture whose files, like foo_arm64.go, are parsed, the host's by default",
+	},
+	&cli.BoolFlag{
+		Name:  flattenAllOfFlag,
+		Usage: "Merge allOf compositions into single schemas for renderers without allOf support, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  enumRefsFlag,
+		Usage: "Refer to definitions of enum types instead of repeating their values in every field, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  cacheDirFlag,
+		Usage: "Directory the parsed docs are kept in to skip parsing as long as none of their source files change",
+	},
+	&cli.BoolFlag{
+		Name:  noCacheFlag,
+		Usage: "Neither use nor update the docs kept in the cacheDir, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  dependencyPrefixFlag,
+		Usage: "Module path prefixes of dependencies whose packages are parsed once a type refers to them, comma separated",
+	},
+	&cli.StringFlag{
+		Name:  embeddedModeFlag,
+		Value: swag.EmbeddedFlatten,
+		Usage: "Render embedded structs by flattening their properties or composing their definitions with allOf, flatten or allof",
+	},
+	&cli.StringFlag{
+		Name:  routerPrefixTrimFlag,
+		Usage: "Leading path prefix, like /api, which is trimmed from every @Router path",
+	},
+	&cli.BoolFlag{
+		Name:  summaryFromFuncFlag,
+		Usage: "Derive the summary of operations without @Summary from the name of their function, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  instancesFlag,
+		Usage: "Instances generated by one run into directories of the output named after them, comma separated name:dir:tags with optional dir and build tags, tags separated by |",
+	},
+	&cli.StringFlag{
+		Name:  treatAsStringFlag,
+		Usage: "Full names of types, like model.Status or time.Duration, which are rendered as plain strings, comma separated",
+	},
+	&cli.BoolFlag{
+		Name:  nameInlineRespFlag,
+		Usage: "Turn inline response objects of operations with an @ID into definitions named like getUser_200, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  int64AsStringFlag,
+		Usage: "Render int64 and uint64 fields as strings with the int64 format, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  jsonNumberFlag,
+		Usage: "Render json.Number fields as strings instead of numbers, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  nullableOmitFlag,
+		Usage: "Never require pointer fields with the json omitempty option, whatever binding or validate tags say, disabled by default",
+	},
+	&cli.BoolFlag{
+		Name:  omitEmptyFlag,
+		Usage: "Add the x-omitempty extension to properties with the json omitempty option, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  readOnlyFlag,
+		Usage: "Glob patterns, like ID or *At, of property names which are marked readOnly, comma separated",
+	},
+	&cli.StringFlag{
+		Name:  writeOnlyFlag,
+		Usage: "Glob patterns, like Password, of property names which are marked writeOnly, comma separated",
+	},
+	&cli.BoolFlag{
+		Name:  strictFieldTypesFlag,
+		Usage: "Fail on func and chan fields instead of skipping them, disabled by default",
+	},
+	&cli.StringFlag{
+		Name:  validateTagNameFlag,
+		Value: "validate",
+		Usage: "Struct tag with go-playground/validator rules turned into validation keywords, like binding with Gin",
+	},
+	&cli.BoolFlag{
+		Name:  diagnosticsFlag,
+		Usage: "Report all malformed operation annotations with their positions instead of the first one, disabled by default",
+	},
 }
 
 func initAction(c *cli.Context) error {
@@ -98,22 +266,91 @@ func initAction(c *cli.Context) error {
 		return fmt.Errorf("not supported %s propertyStrategy", strategy)
 	}
 
+	embeddedMode := c.String(embeddedModeFlag)
+
+	switch embeddedMode {
+	case swag.EmbeddedFlatten, swag.EmbeddedAllOf:
+	default:
+		return fmt.Errorf("not supported %s embeddedMode", embeddedMode)
+	}
+
+	instances, err := parseInstances(c.String(instancesFlag))
+	if err != nil {
+		return err
+	}
+
 	return gen.New().Build(&gen.Config{
-		SearchDir:           c.String(searchDirFlag),
-		Excludes:            c.String(excludeFlag),
-		MainAPIFile:         c.String(generalInfoFlag),
-		PropNamingStrategy:  strategy,
-		OutputDir:           c.String(outputFlag),
-		ParseVendor:         c.Bool(parseVendorFlag),
-		ParseDependency:     c.Bool(parseDependencyFlag),
-		MarkdownFilesDir:    c.String(markdownFilesFlag),
-		ParseInternal:       c.Bool(parseInternalFlag),
-		GeneratedTime:       c.Bool(generatedTimeFlag),
-		CodeExampleFilesDir: c.String(codeExampleFilesFlag),
-		ParseDepth:          c.Int(parseDepthFlag),
+		SearchDir:             c.String(searchDirFlag),
+		Excludes:              c.String(excludeFlag),
+		MainAPIFile:           c.String(generalInfoFlag),
+		PropNamingStrategy:    strategy,
+		EmbeddedMode:          embeddedMode,
+		OutputDir:             c.String(outputFlag),
+		ParseVendor:           c.Bool(parseVendorFlag),
+		ParseDependency:       c.Bool(parseDependencyFlag),
+		MarkdownFilesDir:      c.String(markdownFilesFlag),
+		ParseInternal:         c.Bool(parseInternalFlag),
+		GeneratedTime:         c.Bool(generatedTimeFlag),
+		CodeExampleFilesDir:   c.String(codeExampleFilesFlag),
+		ParseDepth:            c.Int(parseDepthFlag),
+		StripInternal:         c.Bool(stripInternalFlag),
+		CommentSources:        c.String(commentSourcesFlag),
+		FakerTag:              c.String(fakerTagFlag),
+		ParseUnexportedFields: c.Bool(parseUnexportedFlag),
+		DryRun:                c.Bool(dryRunFlag),
+		AutoCreateTags:        c.Bool(autoCreateTagsFlag),
+		InstanceName:          c.String(instanceNameFlag),
+		OpenAPI3:              c.Bool(openAPI3Flag),
+		OpenAPI31:             c.Bool(openAPI31Flag),
+		ParseFuncLocalTypes:   c.Bool(parseFuncLocalFlag),
+		BuildTags:             c.String(tagsFlag),
+		GOOS:                  c.String(goosFlag),
+		GOARCH:                c.String(goarchFlag),
+		FlattenAllOf:          c.Bool(flattenAllOfFlag),
+		EnumRefs:              c.Bool(enumRefsFlag),
+		CacheDir:              c.String(cacheDirFlag),
+		NoCache:               c.Bool(noCacheFlag),
+		DependencyPrefixes:    c.String(dependencyPrefixFlag),
+		RouterPrefixTrim:      c.String(routerPrefixTrimFlag),
+		SummaryFromFuncName:   c.Bool(summaryFromFuncFlag),
+		Instances:             instances,
+		TreatAsString:         c.String(treatAsStringFlag),
+		NameInlineResponses:   c.Bool(nameInlineRespFlag),
+		Int64AsString:         c.Bool(int64AsStringFlag),
+		JSONNumberAsString:    c.Bool(jsonNumberFlag),
+		NullableOmitEmpty:     c.Bool(nullableOmitFlag),
+		OmitEmptyExtension:    c.Bool(omitEmptyFlag),
+		ReadOnlyPatterns:      c.String(readOnlyFlag),
+		WriteOnlyPatterns:     c.String(writeOnlyFlag),
+		StrictFieldTypes:      c.Bool(strictFieldTypesFlag),
+		ValidateTagName:       c.String(validateTagNameFlag),
+		Diagnostics:           c.Bool(diagnosticsFlag),
 	})
 }
 
+// parseInstances parses the instances of the instances flag, like v1:./api/v1,v2:./api/v2:v2|beta
+func parseInstances(value string) ([]gen.Instance, error) {
+	var instances []gen.Instance
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		parts := strings.SplitN(field, ":", 3)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("instance without name: %s", field)
+		}
+		instance := gen.Instance{Name: parts[0]}
+		if len(parts) > 1 {
+			instance.SearchDir = parts[1]
+		}
+		if len(parts) > 2 {
+			instance.BuildTags = strings.ReplaceAll(parts[2], "|", ",")
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
 func main() {
 	fmt.Println("Swag version: ", swag.Version)
 	app := cli.NewApp()
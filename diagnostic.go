@@ -0,0 +1,43 @@
+package swag
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// Diagnostic a problem found while parsing, located at the annotation it was found in
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error implements error
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+}
+
+// Diagnostics all problems found while parsing, returned by ParseAPI of a Parser with CollectDiagnostics set
+type Diagnostics []Diagnostic
+
+// Error implements error
+func (d Diagnostics) Error() string {
+	messages := make([]string, 0, len(d))
+	for _, diagnostic := range d {
+		messages = append(messages, diagnostic.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// addDiagnostic records err at pos of fileName, pos is resolved by the file set of parser if the file was parsed by it.
+func (parser *Parser) addDiagnostic(fileName string, pos token.Pos, err error) {
+	diagnostic := Diagnostic{File: fileName, Message: err.Error()}
+	if position := parser.fileSet.Position(pos); position.IsValid() {
+		diagnostic.File = position.Filename
+		diagnostic.Line = position.Line
+		diagnostic.Column = position.Column
+	}
+	parser.diagnostics = append(parser.diagnostics, diagnostic)
+}
@@ -0,0 +1,252 @@
+package gen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Nerzal/swag"
+	"github.com/go-openapi/spec"
+)
+
+// docCache is a parsed swagger doc kept in the cache dir along with the files it was parsed from,
+// so a build can reuse it as long as none of them changed.
+type docCache struct {
+	// Dirs the packages the doc was parsed from, a new Go file in any of them invalidates the doc
+	Dirs []string `json:"dirs"`
+
+	// Files every input of the doc, the Go files of Dirs and SearchDir, the go.mod and go.sum of the module of SearchDir,
+	// markdown files, code examples and comment sources
+	Files map[string]cachedFile `json:"files"`
+
+	Swagger *spec.Swagger `json:"swagger"`
+}
+
+// cachedFile identifies the content of a file, its modification time and size spare hashing it if they didn't change.
+type cachedFile struct {
+	ModTime int64  `json:"modTime"`
+	Size    int64  `json:"size"`
+	Hash    string `json:"hash"`
+}
+
+// cacheFileName returns the path of the cached doc of config, which depends on every option and the swag version.
+func cacheFileName(config *Config) (string, error) {
+	key, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append(key, swag.Version...))
+	return filepath.Join(config.CacheDir, "swag-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadCachedSwagger returns the cached doc of config, or nil if there's none or any of its inputs changed.
+func loadCachedSwagger(config *Config) *spec.Swagger {
+	if config.CacheDir == "" || config.NoCache {
+		return nil
+	}
+	fileName, err := cacheFileName(config)
+	if err != nil {
+		return nil
+	}
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	var cache docCache
+	if err := json.Unmarshal(b, &cache); err != nil || cache.Swagger == nil {
+		return nil
+	}
+
+	inputs, err := cacheInputs(config, cache.Dirs)
+	if err != nil || len(inputs) != len(cache.Files) {
+		return nil
+	}
+	for _, path := range inputs {
+		cached, ok := cache.Files[path]
+		if !ok {
+			return nil
+		}
+		if current, err := identifyFile(path, &cached); err != nil || current.Hash != cached.Hash {
+			return nil
+		}
+	}
+	return cache.Swagger
+}
+
+// saveCachedSwagger keeps swagger, parsed from the Go files sourceFiles, in the cache dir of config.
+func saveCachedSwagger(config *Config, swagger *spec.Swagger, sourceFiles []string) error {
+	if config.CacheDir == "" || config.NoCache {
+		return nil
+	}
+	fileName, err := cacheFileName(config)
+	if err != nil {
+		return err
+	}
+
+	dirs := map[string]bool{}
+	for _, path := range sourceFiles {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		dirs[dir] = true
+	}
+	cache := docCache{Files: map[string]cachedFile{}, Swagger: swagger}
+	for dir := range dirs {
+		cache.Dirs = append(cache.Dirs, dir)
+	}
+	sort.Strings(cache.Dirs)
+
+	inputs, err := cacheInputs(config, cache.Dirs)
+	if err != nil {
+		return err
+	}
+	for _, path := range inputs {
+		file, err := identifyFile(path, nil)
+		if err != nil {
+			return err
+		}
+		cache.Files[path] = file
+	}
+
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.CacheDir, os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, b, 0644)
+}
+
+// cacheInputs returns the absolute paths of the files a doc parsed with config from the packages dirs depends on.
+func cacheInputs(config *Config, dirs []string) ([]string, error) {
+	inputs := map[string]bool{}
+	addFile := func(path string) error {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		inputs[path] = true
+		return nil
+	}
+	walkFiles := func(root string, match func(path string) bool) error {
+		if root == "" {
+			return nil
+		}
+		return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !match(path) {
+				return nil
+			}
+			return addFile(path)
+		})
+	}
+	isGoFile := func(path string) bool {
+		return filepath.Ext(path) == ".go"
+	}
+	anyFile := func(string) bool {
+		return true
+	}
+
+	for _, dir := range dirs {
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isGoFile(entry.Name()) {
+				inputs[filepath.Join(dir, entry.Name())] = true
+			}
+		}
+	}
+	if err := walkFiles(config.SearchDir, isGoFile); err != nil {
+		return nil, err
+	}
+	// the required versions and replaced modules decide which dependency sources are parsed
+	if modDir, err := moduleDir(config.SearchDir); err == nil && modDir != "" {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			if _, err := os.Stat(filepath.Join(modDir, name)); err == nil {
+				inputs[filepath.Join(modDir, name)] = true
+			}
+		}
+	}
+	if err := walkFiles(config.MarkdownFilesDir, anyFile); err != nil {
+		return nil, err
+	}
+	if err := walkFiles(config.CodeExampleFilesDir, anyFile); err != nil {
+		return nil, err
+	}
+	for _, pattern := range strings.Split(config.CommentSources, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(config.SearchDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			if err := addFile(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(inputs))
+	for path := range inputs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// moduleDir returns the absolute directory of the go.mod of the module dir belongs to, or an empty string
+// if there's none.
+func moduleDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// identifyFile returns the identity of the file at path. Its content is only hashed if its modification
+// time or size differ from the ones of known.
+func identifyFile(path string, known *cachedFile) (cachedFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return cachedFile{}, err
+	}
+	file := cachedFile{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+	if known != nil && known.ModTime == file.ModTime && known.Size == file.Size {
+		file.Hash = known.Hash
+		return file, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cachedFile{}, err
+	}
+	sum := sha256.Sum256(b)
+	file.Hash = hex.EncodeToString(sum[:])
+	return file, nil
+}
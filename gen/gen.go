@@ -40,7 +40,7 @@ type Config struct {
 	// SearchDir the swag would be parse
 	SearchDir string
 
-	// excludes dirs and files in SearchDir,comma separated
+	// excludes dirs and files in SearchDir,comma separated, also as path prefixes or glob patterns relative to SearchDir
 	Excludes string
 
 	// OutputDir represents the output directory for all the generated files
@@ -52,6 +52,9 @@ type Config struct {
 	// PropNamingStrategy represents property naming strategy like snakecase,camelcase,pascalcase
 	PropNamingStrategy string
 
+	// EmbeddedMode how embedded structs are rendered, flatten by default or allof
+	EmbeddedMode string
+
 	// ParseVendor whether swag should be parse vendor folder
 	ParseVendor bool
 
@@ -61,6 +64,9 @@ type Config struct {
 	// ParseInternal whether swag should parse internal packages
 	ParseInternal bool
 
+	// ParseUnexportedFields whether swag should include unexported fields with an explicit json or swaggertype tag
+	ParseUnexportedFields bool
+
 	// MarkdownFilesDir used to find markdownfiles, which can be used for tag descriptions
 	MarkdownFilesDir string
 
@@ -72,29 +78,188 @@ type Config struct {
 
 	// ParseDepth dependency parse depth
 	ParseDepth int
+
+	// StripInternal whether swag should drop parameters marked with x-internal(true) from the output
+	StripInternal bool
+
+	// CommentSources glob patterns of non-Go files with additional annotations, comma separated
+	CommentSources string
+
+	// FakerTag the struct tag used to generate example values, like faker:"email"
+	FakerTag string
+
+	// DryRun whether swag should only log a summary of the generated docs instead of writing them
+	DryRun bool
+
+	// AutoCreateTags whether tags used by operations are added to the root tags if they aren't declared
+	AutoCreateTags bool
+
+	// InstanceName the name the docs are registered under, like v2.
+	// Builds of several instances, like API versions, need their own OutputDir each.
+	InstanceName string
+
+	// OpenAPI3 whether swag should generate OpenAPI 3.0 docs instead of Swagger 2.0 ones
+	OpenAPI3 bool
+
+	// OpenAPI31 whether swag should generate OpenAPI 3.1 docs instead of Swagger 2.0 ones
+	OpenAPI31 bool
+
+	// ParseFuncLocalTypes whether operations may refer to types declared in their function
+	ParseFuncLocalTypes bool
+
+	// BuildTags comma separated build tags satisfied by the parsed files besides GOOS, GOARCH and the release tags
+	BuildTags string
+
+	// GOOS the target operating system whose files are parsed, the host's by default
+	GOOS string
+
+	// GOARCH the target architecture whose files are parsed, the host's by default
+	GOARCH string
+
+	// FlattenAllOf whether allOf compositions are merged, with the definitions they refer to, into single schemas
+	FlattenAllOf bool
+
+	// EnumRefs whether enum types become definitions which fields refer to instead of repeating their values
+	EnumRefs bool
+
+	// CacheDir the directory the parsed doc is kept in, to skip parsing as long as none of its source files change
+	CacheDir string
+
+	// NoCache whether swag should neither use nor update the doc kept in CacheDir
+	NoCache bool
+
+	// DependencyPrefixes comma separated module path prefixes of the dependencies whose packages are parsed
+	// once a type refers to them, instead of the whole dependency graph parsed by ParseDependency
+	DependencyPrefixes string
+
+	// RouterPrefixTrim leading path prefix, like /api, which is trimmed from every @Router path
+	RouterPrefixTrim string
+
+	// SummaryFromFuncName whether operations without @Summary get one derived from the name of their function
+	SummaryFromFuncName bool
+
+	// TreatAsString comma separated full names of types, like model.Status, which are rendered as plain strings
+	TreatAsString string
+
+	// NameInlineResponses whether the inline response objects of operations with an id become definitions named
+	// by the id and the status, like getUser_200
+	NameInlineResponses bool
+
+	// Int64AsString whether 64-bit integers are rendered as strings with the int64 format
+	Int64AsString bool
+
+	// JSONNumberAsString whether json.Number fields are rendered as strings instead of numbers
+	JSONNumberAsString bool
+
+	// NullableOmitEmpty whether pointer fields with the json omitempty option are never required
+	NullableOmitEmpty bool
+
+	// OmitEmptyExtension whether properties with the json omitempty option get the x-omitempty extension
+	OmitEmptyExtension bool
+
+	// ReadOnlyPatterns comma separated glob patterns, like ID or *At, of property names which are marked readOnly
+	ReadOnlyPatterns string
+
+	// WriteOnlyPatterns comma separated glob patterns, like Password, of property names which are marked writeOnly
+	WriteOnlyPatterns string
+
+	// StrictFieldTypes whether swag should fail on func and chan fields instead of skipping them
+	StrictFieldTypes bool
+
+	// ValidateTagName the struct tag with go-playground/validator rules, validate by default or binding with Gin
+	ValidateTagName string
+
+	// Diagnostics whether swag should report all malformed operation annotations with their positions at once
+	Diagnostics bool
+
+	// Instances passes of a single Build, each generating the docs of an instance, like an API version, into
+	// a directory of OutputDir named after the instance. The other options are shared by the passes.
+	Instances []Instance
+}
+
+// Instance a pass of a Build generating the docs of an instance.
+type Instance struct {
+	// Name the docs are registered under, which also names their output directory
+	Name string
+
+	// SearchDir the directory parsed for the instance, Config.SearchDir by default
+	SearchDir string
+
+	// BuildTags comma separated build tags satisfied by the files parsed for the instance besides Config.BuildTags
+	BuildTags string
+}
+
+// Summary counts the main parts of generated docs.
+type Summary struct {
+	Paths       int
+	Operations  int
+	Definitions int
+	Tags        int
 }
 
 // Build builds swagger json file  for given searchDir and mainAPIFile. Returns json
 func (g *Gen) Build(config *Config) error {
+	if len(config.Instances) == 0 {
+		return g.build(config)
+	}
+
+	for _, instance := range config.Instances {
+		if instance.Name == "" {
+			return errors.New("instance without name")
+		}
+		instanceConfig := *config
+		instanceConfig.Instances = nil
+		instanceConfig.InstanceName = instance.Name
+		instanceConfig.OutputDir = filepath.Join(config.OutputDir, instance.Name)
+		if instance.SearchDir != "" {
+			instanceConfig.SearchDir = instance.SearchDir
+		}
+		if instance.BuildTags != "" {
+			instanceConfig.BuildTags = strings.Join(append(splitList(config.BuildTags), splitList(instance.BuildTags)...), ",")
+		}
+
+		if err := g.build(&instanceConfig); err != nil {
+			return errors.Wrapf(err, "instance %s", instance.Name)
+		}
+	}
+	return nil
+}
+
+// build builds the docs of config.
+func (g *Gen) build(config *Config) error {
 	if _, err := os.Stat(config.SearchDir); os.IsNotExist(err) {
 		return errors.Wrap(fmt.Errorf("dir: %s does not exist", config.SearchDir), "could not find specified searchDir")
 	}
 
 	log.Println("Generate swagger docs....")
-	p := swag.New(swag.SetMarkdownFileDirectory(config.MarkdownFilesDir),
-		swag.SetExcludedDirsAndFiles(config.Excludes),
-		swag.SetCodeExamplesDirectory(config.CodeExampleFilesDir))
-	p.PropNamingStrategy = config.PropNamingStrategy
-	p.ParseVendor = config.ParseVendor
-	p.ParseDependency = config.ParseDependency
-	p.ParseInternal = config.ParseInternal
+	swagger := loadCachedSwagger(config)
+	if swagger != nil {
+		log.Printf("none of the source files changed, using the doc cached in %s", config.CacheDir)
+	} else {
+		var err error
+		if swagger, err = g.parse(config); err != nil {
+			return err
+		}
+	}
+	if config.StripInternal {
+		stripInternalParams(swagger)
+	}
+	if config.FlattenAllOf {
+		flattenAllOf(swagger)
+	}
 
-	if err := p.ParseAPI(config.SearchDir, config.MainAPIFile, config.ParseDepth); err != nil {
-		return err
+	if config.DryRun {
+		summary := Summarize(swagger)
+		log.Printf("dry run, nothing written: %d paths, %d operations, %d definitions, %d tags",
+			summary.Paths, summary.Operations, summary.Definitions, summary.Tags)
+		return nil
 	}
-	swagger := p.GetSwagger()
 
-	b, err := g.jsonIndent(swagger)
+	var doc interface{} = swagger
+	if openAPI := convertOpenAPI(swagger, config); openAPI != nil {
+		doc = openAPI
+	}
+	b, err := g.jsonIndent(doc)
 	if err != nil {
 		return err
 	}
@@ -146,6 +311,269 @@ func (g *Gen) Build(config *Config) error {
 	return nil
 }
 
+// parse parses the swagger doc of config, which is kept in its cache dir if any.
+func (g *Gen) parse(config *Config) (*spec.Swagger, error) {
+	p := swag.New(swag.SetMarkdownFileDirectory(config.MarkdownFilesDir),
+		swag.SetExcludedDirsAndFiles(config.Excludes),
+		swag.SetCodeExamplesDirectory(config.CodeExampleFilesDir))
+	p.PropNamingStrategy = config.PropNamingStrategy
+	p.ParseVendor = config.ParseVendor
+	p.ParseDependency = config.ParseDependency
+	p.ParseInternal = config.ParseInternal
+	p.ParseUnexportedFields = config.ParseUnexportedFields
+	p.ParseFuncLocalTypes = config.ParseFuncLocalTypes
+	p.GOOS = config.GOOS
+	p.GOARCH = config.GOARCH
+	p.BuildTags = splitList(config.BuildTags)
+	p.Int64AsString = config.Int64AsString
+	p.EmbeddedMode = config.EmbeddedMode
+	p.StrictFieldTypes = config.StrictFieldTypes
+	if config.ValidateTagName != "" {
+		p.ValidateTagName = config.ValidateTagName
+	}
+	p.OpenAPI3 = config.OpenAPI3 || config.OpenAPI31
+	p.OmitEmptyExtension = config.OmitEmptyExtension
+	p.EnumRefs = config.EnumRefs
+	p.FakerTag = config.FakerTag
+	p.CommentSources = splitList(config.CommentSources)
+	p.ReadOnlyPatterns = splitList(config.ReadOnlyPatterns)
+	p.WriteOnlyPatterns = splitList(config.WriteOnlyPatterns)
+	p.AutoCreateTags = config.AutoCreateTags
+	p.SummaryFromFuncName = config.SummaryFromFuncName
+	p.DependencyPrefixes = splitList(config.DependencyPrefixes)
+	p.RouterPrefixTrim = config.RouterPrefixTrim
+	p.CollectDiagnostics = config.Diagnostics
+	p.TreatAsString = splitList(config.TreatAsString)
+	p.JSONNumberAsString = config.JSONNumberAsString
+	p.NullableOmitEmpty = config.NullableOmitEmpty
+	if config.NameInlineResponses {
+		p.InlineResponseName = swag.OperationResponseName
+	}
+
+	if err := p.ParseAPI(config.SearchDir, config.MainAPIFile, config.ParseDepth); err != nil {
+		return nil, err
+	}
+	swagger := p.GetSwagger()
+	if err := saveCachedSwagger(config, swagger, p.SourceFiles()); err != nil {
+		log.Printf("warning: failed to cache the doc in %s: %s", config.CacheDir, err)
+	}
+	return swagger, nil
+}
+
+// splitList splits a comma separated list, dropping empty entries.
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Summarize counts the paths, operations, definitions and distinct tags, declared or used by an operation, of swagger.
+func Summarize(swagger *spec.Swagger) Summary {
+	summary := Summary{Definitions: len(swagger.Definitions)}
+
+	tags := map[string]bool{}
+	for _, tag := range swagger.Tags {
+		tags[tag.Name] = true
+	}
+
+	if swagger.Paths != nil {
+		summary.Paths = len(swagger.Paths.Paths)
+		for _, pathItem := range swagger.Paths.Paths {
+			for _, operation := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post,
+				pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+				if operation == nil {
+					continue
+				}
+				summary.Operations++
+				for _, tag := range operation.Tags {
+					tags[tag] = true
+				}
+			}
+		}
+	}
+	summary.Tags = len(tags)
+
+	return summary
+}
+
+// stripInternalParams removes the parameters marked with x-internal from every operation
+func stripInternalParams(swagger *spec.Swagger) {
+	if swagger.Paths == nil {
+		return
+	}
+
+	for _, pathItem := range swagger.Paths.Paths {
+		for _, operation := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post,
+			pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+			if operation == nil {
+				continue
+			}
+
+			params := operation.Parameters[:0]
+			for _, param := range operation.Parameters {
+				if internal, ok := param.Extensions.GetBool("x-internal"); ok && internal {
+					continue
+				}
+				params = append(params, param)
+			}
+			operation.Parameters = params
+		}
+	}
+}
+
+// convertOpenAPI converts swagger to the OpenAPI version asked for by config, nil if Swagger 2.0 docs are generated.
+func convertOpenAPI(swagger *spec.Swagger, config *Config) *swag.OpenAPI3 {
+	switch {
+	case config.OpenAPI31:
+		return swag.ConvertToOpenAPI31(swagger)
+	case config.OpenAPI3:
+		return swag.ConvertToOpenAPI3(swagger)
+	}
+	return nil
+}
+
+// flattenAllOf merges the members of allOf compositions, with the definitions they refer to, into single schemas
+// for renderers which don't support allOf.
+func flattenAllOf(swagger *spec.Swagger) {
+	f := &allOfFlattener{definitions: swagger.Definitions, resolving: map[string]bool{}}
+	for name, schema := range swagger.Definitions {
+		swagger.Definitions[name] = f.flatten(schema)
+	}
+
+	if swagger.Paths == nil {
+		return
+	}
+	for _, pathItem := range swagger.Paths.Paths {
+		for _, operation := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post,
+			pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+			if operation == nil {
+				continue
+			}
+
+			for i := range operation.Parameters {
+				if schema := operation.Parameters[i].Schema; schema != nil {
+					flattened := f.flatten(*schema)
+					operation.Parameters[i].Schema = &flattened
+				}
+			}
+			if operation.Responses == nil {
+				continue
+			}
+			if response := operation.Responses.Default; response != nil && response.Schema != nil {
+				flattened := f.flatten(*response.Schema)
+				response.Schema = &flattened
+			}
+			for code, response := range operation.Responses.StatusCodeResponses {
+				if response.Schema != nil {
+					flattened := f.flatten(*response.Schema)
+					response.Schema = &flattened
+					operation.Responses.StatusCodeResponses[code] = response
+				}
+			}
+		}
+	}
+}
+
+type allOfFlattener struct {
+	definitions spec.Definitions
+
+	// resolving definitions being merged now, which aren't merged into themselves again
+	resolving map[string]bool
+}
+
+func (f *allOfFlattener) flatten(schema spec.Schema) spec.Schema {
+	if schema.Items != nil && schema.Items.Schema != nil {
+		items := f.flatten(*schema.Items.Schema)
+		schema.Items = &spec.SchemaOrArray{Schema: &items}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		additionalProperties := f.flatten(*schema.AdditionalProperties.Schema)
+		schema.AdditionalProperties = &spec.SchemaOrBool{Allows: true, Schema: &additionalProperties}
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(spec.SchemaProperties, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = f.flatten(property)
+		}
+		schema.Properties = properties
+	}
+	if len(schema.AllOf) == 0 {
+		return schema
+	}
+
+	allOf := schema.AllOf
+	schema.AllOf = nil
+	schema.Required = append([]string(nil), schema.Required...)
+	if len(schema.Extensions) > 0 {
+		extensions := make(spec.Extensions, len(schema.Extensions))
+		for key, value := range schema.Extensions {
+			extensions[key] = value
+		}
+		schema.Extensions = extensions
+	}
+	for _, member := range allOf {
+		if ref := member.Ref.String(); ref != "" {
+			name := strings.TrimPrefix(ref, "#/definitions/")
+			definition, ok := f.definitions[name]
+			if !ok || f.resolving[name] {
+				schema.AllOf = append(schema.AllOf, member)
+				continue
+			}
+			f.resolving[name] = true
+			member = f.flatten(definition)
+			delete(f.resolving, name)
+		} else {
+			member = f.flatten(member)
+		}
+		mergeSchema(&schema, member)
+	}
+	return schema
+}
+
+// mergeSchema adds the properties and required properties of src to dst, whose properties override the ones of
+// earlier members of the composition. Other attributes of dst win over the ones of src.
+func mergeSchema(dst *spec.Schema, src spec.Schema) {
+	if len(dst.Type) == 0 {
+		dst.Type = src.Type
+	}
+	if dst.Format == "" {
+		dst.Format = src.Format
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	if dst.Items == nil {
+		dst.Items = src.Items
+	}
+	if dst.AdditionalProperties == nil {
+		dst.AdditionalProperties = src.AdditionalProperties
+	}
+	if len(src.Properties) > 0 && dst.Properties == nil {
+		dst.Properties = make(spec.SchemaProperties, len(src.Properties))
+	}
+	for name, property := range src.Properties {
+		dst.Properties[name] = property
+	}
+	for _, name := range src.Required {
+		required := false
+		for _, existing := range dst.Required {
+			required = required || existing == name
+		}
+		if !required {
+			dst.Required = append(dst.Required, name)
+		}
+	}
+	for key, value := range src.Extensions {
+		if _, ok := dst.Extensions[key]; !ok {
+			dst.AddExtension(key, value)
+		}
+	}
+}
+
 func (g *Gen) writeFile(b []byte, file string) error {
 	f, err := os.Create(file)
 	if err != nil {
@@ -168,8 +596,10 @@ func (g *Gen) formatSource(src []byte) []byte {
 func (g *Gen) writeGoDoc(packageName string, output io.Writer, swagger *spec.Swagger, config *Config) error {
 	generator, err := template.New("swagger_info").Funcs(template.FuncMap{
 		"printDoc": func(v string) string {
-			// Add schemes
-			v = "{\n    \"schemes\": {{ marshal .Schemes }}," + v[1:]
+			if !config.OpenAPI3 && !config.OpenAPI31 {
+				// Add schemes
+				v = "{\n    \"schemes\": {{ marshal .Schemes }}," + v[1:]
+			}
 			// Sanitize backticks
 			return strings.Replace(v, "`", "`+\"`\"+`", -1)
 		},
@@ -209,19 +639,33 @@ func (g *Gen) writeGoDoc(packageName string, output io.Writer, swagger *spec.Swa
 		},
 	}
 
+	var doc interface{} = swaggerSpec
+	if openAPI := convertOpenAPI(swaggerSpec, config); openAPI != nil {
+		// like host and basePath, the server is taken from SwaggerInfo
+		openAPI.Servers = []swag.OpenAPI3Server{{URL: "{{ if .Host }}//{{ .Host }}{{ end }}{{ .BasePath }}"}}
+		doc = openAPI
+	}
+
 	// crafted docs.json
-	buf, err := g.jsonIndent(swaggerSpec)
+	buf, err := g.jsonIndent(doc)
 	if err != nil {
 		return err
 	}
 
 	buffer := &bytes.Buffer{}
+	// the default instance keeps registering under swag.Name
+	instanceName := config.InstanceName
+	if instanceName == swag.Name {
+		instanceName = ""
+	}
+
 	err = generator.Execute(buffer, struct {
 		Timestamp     time.Time
 		GeneratedTime bool
 		Doc           string
 		Host          string
 		PackageName   string
+		InstanceName  string
 		BasePath      string
 		Schemes       []string
 		Title         string
@@ -233,6 +677,7 @@ func (g *Gen) writeGoDoc(packageName string, output io.Writer, swagger *spec.Swa
 		Doc:           string(buf),
 		Host:          swagger.Host,
 		PackageName:   packageName,
+		InstanceName:  instanceName,
 		BasePath:      swagger.BasePath,
 		Schemes:       swagger.Schemes,
 		Title:         swagger.Info.Title,
@@ -311,6 +756,6 @@ func (s *s) ReadDoc() string {
 }
 
 func init() {
-	swag.Register(swag.Name, &s{})
+	swag.Register({{ if .InstanceName }}{{ printf "%q" .InstanceName }}{{ else }}swag.Name{{ end }}, &s{})
 }
 `
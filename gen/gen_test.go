@@ -1,12 +1,15 @@
 package gen
 
 import (
+	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
 
+	"github.com/Nerzal/swag"
 	"github.com/go-openapi/spec"
 	"github.com/stretchr/testify/assert"
 )
@@ -35,6 +38,133 @@ func TestGen_Build(t *testing.T) {
 	}
 }
 
+func TestGen_BuildDryRun(t *testing.T) {
+	config := &Config{
+		SearchDir:   "../testdata/simple",
+		MainAPIFile: "./main.go",
+		OutputDir:   "../testdata/simple/docs_dry_run",
+		DryRun:      true,
+	}
+	assert.NoError(t, New().Build(config))
+
+	_, err := os.Stat(config.OutputDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGen_BuildInstances(t *testing.T) {
+	configs := []*Config{
+		{
+			SearchDir:    "../testdata/simple",
+			MainAPIFile:  "./main.go",
+			OutputDir:    "../testdata/simple/docs/v1",
+			InstanceName: "v1",
+		},
+		{
+			SearchDir:    "../testdata/simple2",
+			MainAPIFile:  "./main.go",
+			OutputDir:    "../testdata/simple/docs/v2",
+			InstanceName: "v2",
+		},
+	}
+	for _, config := range configs {
+		assert.NoError(t, New().Build(config))
+		defer os.RemoveAll(config.OutputDir)
+
+		for _, fileName := range []string{"docs.go", "swagger.json", "swagger.yaml"} {
+			_, err := os.Stat(filepath.Join(config.OutputDir, fileName))
+			assert.NoError(t, err)
+		}
+
+		docs, err := ioutil.ReadFile(filepath.Join(config.OutputDir, "docs.go"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(docs), `swag.Register("`+config.InstanceName+`", &s{})`)
+	}
+}
+
+func TestGen_BuildMultiplePasses(t *testing.T) {
+	config := &Config{
+		SearchDir:   "../testdata/simple",
+		MainAPIFile: "./main.go",
+		OutputDir:   "../testdata/simple/docs/versions",
+		Instances: []Instance{
+			{Name: "v1"},
+			{Name: "v2", SearchDir: "../testdata/simple2"},
+		},
+	}
+	assert.NoError(t, New().Build(config))
+	defer os.RemoveAll(config.OutputDir)
+
+	titles := map[string]string{}
+	for _, name := range []string{"v1", "v2"} {
+		for _, fileName := range []string{"docs.go", "swagger.json", "swagger.yaml"} {
+			_, err := os.Stat(filepath.Join(config.OutputDir, name, fileName))
+			assert.NoError(t, err)
+		}
+
+		docs, err := ioutil.ReadFile(filepath.Join(config.OutputDir, name, "docs.go"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(docs), `swag.Register("`+name+`", &s{})`)
+
+		b, err := ioutil.ReadFile(filepath.Join(config.OutputDir, name, "swagger.json"))
+		assert.NoError(t, err)
+		var swagger spec.Swagger
+		assert.NoError(t, json.Unmarshal(b, &swagger))
+		titles[name] = swagger.Info.Title
+	}
+	assert.NotEmpty(t, titles["v1"])
+	assert.NotEmpty(t, titles["v2"])
+
+	// one root parsed with the build tags of each instance
+	config = &Config{
+		SearchDir:   "../testdata/versions",
+		MainAPIFile: "./main.go",
+		OutputDir:   "../testdata/versions/docs",
+		Instances: []Instance{
+			{Name: "v1"},
+			{Name: "v2", BuildTags: "v2"},
+		},
+	}
+	assert.NoError(t, New().Build(config))
+	defer os.RemoveAll(config.OutputDir)
+
+	for name, path := range map[string]string{"v1": "/v1/users", "v2": "/v2/users"} {
+		b, err := ioutil.ReadFile(filepath.Join(config.OutputDir, name, "swagger.json"))
+		assert.NoError(t, err)
+		var swagger spec.Swagger
+		assert.NoError(t, json.Unmarshal(b, &swagger))
+		assert.Len(t, swagger.Paths.Paths, 1)
+		assert.Contains(t, swagger.Paths.Paths, path)
+	}
+
+	assert.EqualError(t, New().Build(&Config{SearchDir: "../testdata/simple", Instances: []Instance{{}}}), "instance without name")
+}
+
+func TestGen_BuildOpenAPI3(t *testing.T) {
+	config := &Config{
+		SearchDir:   "../testdata/simple",
+		MainAPIFile: "./main.go",
+		OutputDir:   "../testdata/simple/docs/openapi3",
+		OpenAPI3:    true,
+	}
+	assert.NoError(t, New().Build(config))
+	defer os.RemoveAll(config.OutputDir)
+
+	b, err := ioutil.ReadFile(filepath.Join(config.OutputDir, "swagger.json"))
+	assert.NoError(t, err)
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &doc))
+	assert.Equal(t, swag.OpenAPI3Version, doc["openapi"])
+	assert.Contains(t, doc, "components")
+	assert.NotContains(t, doc, "swagger")
+	assert.NotContains(t, doc, "definitions")
+
+	docs, err := ioutil.ReadFile(filepath.Join(config.OutputDir, "docs.go"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(docs), `"openapi": "`+swag.OpenAPI3Version+`"`)
+	assert.Contains(t, string(docs), `"url": "{{ if .Host }}//{{ .Host }}{{ end }}{{ .BasePath }}"`)
+	assert.NotContains(t, string(docs), `"schemes"`)
+}
+
 func TestGen_BuildSnakecase(t *testing.T) {
 	searchDir := "../testdata/simple2"
 	config := &Config{
@@ -126,6 +256,29 @@ func TestGen_jsonToYAML(t *testing.T) {
 	}
 }
 
+func TestGen_jsonToYAMLScalarTypes(t *testing.T) {
+	operation := swag.NewOperation(nil)
+	comments := []string{
+		`@Param flag query bool false "flag" default(true)`,
+		`@Param limit query int false "limit" example(42)`,
+		`@Param name query string false "name" default(true)`,
+	}
+	for _, comment := range comments {
+		assert.NoError(t, operation.ParseComment(comment, nil))
+	}
+
+	jsonData, err := json.Marshal(operation)
+	assert.NoError(t, err)
+
+	yamlData, err := New().jsonToYAML(jsonData)
+	assert.NoError(t, err)
+
+	yaml := string(yamlData)
+	assert.Contains(t, yaml, "default: true\n")
+	assert.Contains(t, yaml, "example: 42\n")
+	assert.Contains(t, yaml, "default: \"true\"\n")
+}
+
 func TestGen_SearchDirIsNotExist(t *testing.T) {
 	searchDir := "../isNotExistDir"
 
@@ -370,3 +523,185 @@ func TestGen_cgoImports(t *testing.T) {
 		os.Remove(expectedFile)
 	}
 }
+
+func TestGen_stripInternalParams(t *testing.T) {
+	internal := spec.HeaderParam("trace_id")
+	internal.AddExtension("x-internal", true)
+	swagger := &spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Paths: &spec.Paths{
+				Paths: map[string]spec.PathItem{
+					"/test": {
+						PathItemProps: spec.PathItemProps{
+							Get: &spec.Operation{
+								OperationProps: spec.OperationProps{
+									Parameters: []spec.Parameter{*spec.QueryParam("q"), *internal},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	stripInternalParams(swagger)
+
+	params := swagger.Paths.Paths["/test"].Get.Parameters
+	assert.Len(t, params, 1)
+	assert.Equal(t, "q", params[0].Name)
+}
+
+func TestGen_Summarize(t *testing.T) {
+	swagger := &spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Tags: []spec.Tag{{TagProps: spec.TagProps{Name: "users"}}},
+			Paths: &spec.Paths{
+				Paths: map[string]spec.PathItem{
+					"/users": {
+						PathItemProps: spec.PathItemProps{
+							Get:  &spec.Operation{OperationProps: spec.OperationProps{Tags: []string{"users"}}},
+							Post: &spec.Operation{OperationProps: spec.OperationProps{Tags: []string{"users", "admin"}}},
+						},
+					},
+					"/health": {
+						PathItemProps: spec.PathItemProps{
+							Get: &spec.Operation{},
+						},
+					},
+				},
+			},
+			Definitions: spec.Definitions{
+				"model.User":  spec.Schema{},
+				"model.Admin": spec.Schema{},
+				"model.Error": spec.Schema{},
+			},
+		},
+	}
+
+	assert.Equal(t, Summary{Paths: 2, Operations: 3, Definitions: 3, Tags: 2}, Summarize(swagger))
+}
+
+func TestGen_flattenAllOf(t *testing.T) {
+	response := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:     []string{"object"},
+			Required: []string{"code"},
+			Properties: spec.SchemaProperties{
+				"code": *spec.Int64Property(),
+				"data": *spec.MapProperty(nil),
+			},
+		},
+	}
+	composed := spec.ComposedSchema(*spec.RefSchema("#/definitions/model.Response"), spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: spec.SchemaProperties{
+				"data": *spec.RefSchema("#/definitions/model.User"),
+			},
+		},
+	})
+	swagger := &spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Paths: &spec.Paths{
+				Paths: map[string]spec.PathItem{
+					"/users/{id}": {
+						PathItemProps: spec.PathItemProps{
+							Get: &spec.Operation{
+								OperationProps: spec.OperationProps{
+									Responses: &spec.Responses{
+										ResponsesProps: spec.ResponsesProps{
+											StatusCodeResponses: map[int]spec.Response{
+												200: *spec.NewResponse().WithSchema(composed),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Definitions: spec.Definitions{
+				"model.Response": response,
+				"model.User":     *spec.StringProperty(),
+			},
+		},
+	}
+
+	b, _ := json.Marshal(swagger.Paths.Paths["/users/{id}"].Get.Responses.StatusCodeResponses[200].Schema)
+	assert.Equal(t, `{"allOf":[{"$ref":"#/definitions/model.Response"},{"type":"object",`+
+		`"properties":{"data":{"$ref":"#/definitions/model.User"}}}]}`, string(b))
+
+	flattenAllOf(swagger)
+
+	b, _ = json.Marshal(swagger.Paths.Paths["/users/{id}"].Get.Responses.StatusCodeResponses[200].Schema)
+	assert.Equal(t, `{"type":"object","required":["code"],"properties":{"code":{"type":"integer","format":"int64"},`+
+		`"data":{"$ref":"#/definitions/model.User"}}}`, string(b))
+
+	// the base definition is left alone
+	assert.Equal(t, response, swagger.Definitions["model.Response"])
+}
+
+func TestGen_BuildCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "swag-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src, err := ioutil.ReadFile("../testdata/single_file_api/main.go")
+	assert.NoError(t, err)
+	searchDir := filepath.Join(dir, "api")
+	assert.NoError(t, os.MkdirAll(searchDir, os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(searchDir, "main.go"), src, 0644))
+
+	config := &Config{
+		SearchDir:   searchDir,
+		MainAPIFile: "./main.go",
+		OutputDir:   filepath.Join(dir, "docs"),
+		CacheDir:    filepath.Join(dir, "cache"),
+	}
+	build := func(config *Config) string {
+		assert.NoError(t, New().Build(config))
+		b, err := ioutil.ReadFile(filepath.Join(config.OutputDir, "swagger.json"))
+		assert.NoError(t, err)
+		var swagger spec.Swagger
+		assert.NoError(t, json.Unmarshal(b, &swagger))
+		return swagger.Info.Title
+	}
+	assert.Equal(t, "Swagger Example API", build(config))
+
+	// mark the cached doc, so builds using it can be told apart from the ones parsing the sources
+	markCache := func() {
+		cacheFile, err := cacheFileName(config)
+		assert.NoError(t, err)
+		b, err := ioutil.ReadFile(cacheFile)
+		assert.NoError(t, err)
+		var cache docCache
+		assert.NoError(t, json.Unmarshal(b, &cache))
+		assert.Equal(t, []string{searchDir}, cache.Dirs)
+		cache.Swagger.Info.Title = "Cached API"
+		b, err = json.Marshal(cache)
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(cacheFile, b, 0644))
+	}
+	markCache()
+
+	assert.Equal(t, "Cached API", build(config))
+
+	noCache := *config
+	noCache.NoCache = true
+	assert.Equal(t, "Swagger Example API", build(&noCache))
+
+	// a new file in a parsed package invalidates the cached doc
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(searchDir, "model.go"), []byte("package main\n"), 0644))
+	assert.Equal(t, "Swagger Example API", build(config))
+
+	// so does a changed go.mod
+	goMod := filepath.Join(dir, "go.mod")
+	assert.NoError(t, ioutil.WriteFile(goMod, []byte("module example.com/api\n"), 0644))
+	assert.Equal(t, "Swagger Example API", build(config))
+	markCache()
+	assert.Equal(t, "Cached API", build(config))
+	assert.NoError(t, ioutil.WriteFile(goMod, []byte("module example.com/api\n\ngo 1.15\n"), 0644))
+	assert.Equal(t, "Swagger Example API", build(config))
+}
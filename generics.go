@@ -0,0 +1,113 @@
+package swag
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// typeArg is a type argument of an instantiated generic type, like model.User of Page[model.User]
+type typeArg struct {
+	//the type argument as written
+	expr ast.Expr
+
+	//ast file where the type argument is written
+	file *ast.File
+
+	//type arguments of the generic type the type argument is written in, if any
+	scope map[string]*typeArg
+}
+
+// parseTypeArg returns the schema of the type argument bound to a type parameter.
+func (parser *Parser) parseTypeArg(arg *typeArg, ref bool) (*spec.Schema, error) {
+	typeArgs := parser.typeArgs
+	parser.typeArgs = arg.scope
+	defer func() {
+		parser.typeArgs = typeArgs
+	}()
+
+	return parser.parseTypeExpr(arg.file, arg.expr, ref)
+}
+
+// getGenericTypeSchema returns the schema of the generic type genericType instantiated with args,
+// written in file. Each instantiation is a type definition of its own.
+func (parser *Parser) getGenericTypeSchema(file *ast.File, genericType ast.Expr, args []ast.Expr, ref bool) (*spec.Schema, error) {
+	typeName := types.ExprString(genericType)
+	typeSpecDef := parser.packages.FindTypeSpec(typeName, file)
+	if typeSpecDef == nil {
+		return nil, fmt.Errorf("cannot find type definition: %s", typeName)
+	}
+	if len(typeSpecDef.TypeParams) != len(args) {
+		return nil, fmt.Errorf("%s expects %d type arguments, got %d", typeSpecDef.FullName(), len(typeSpecDef.TypeParams), len(args))
+	}
+
+	argNames := make([]string, 0, len(args))
+	for _, arg := range args {
+		argNames = append(argNames, parser.typeArgName(arg, file, parser.typeArgs))
+	}
+
+	instance := &TypeSpecDef{
+		PkgPath:    typeSpecDef.PkgPath,
+		File:       typeSpecDef.File,
+		TypeSpec:   typeSpecDef.TypeSpec,
+		TypeParams: typeSpecDef.TypeParams,
+		TypeArgs:   argNames,
+	}
+	if existing, ok := parser.genericDefinitions[instance.FullName()]; ok {
+		return parser.getTypeSpecSchema(existing, ref)
+	}
+
+	instance.typeArgs = make(map[string]*typeArg, len(args))
+	for i, arg := range args {
+		instance.typeArgs[typeSpecDef.TypeParams[i]] = &typeArg{expr: arg, file: file, scope: parser.typeArgs}
+	}
+	parser.genericDefinitions[instance.FullName()] = instance
+
+	return parser.getTypeSpecSchema(instance, ref)
+}
+
+// typeArgName returns the name of a type argument independent of the file it's written in,
+// which is the full name of the type it refers to.
+func (parser *Parser) typeArgName(expr ast.Expr, file *ast.File, scope map[string]*typeArg) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if arg, ok := scope[expr.Name]; ok {
+			return parser.typeArgName(arg.expr, arg.file, arg.scope)
+		}
+	case *ast.StarExpr:
+		return parser.typeArgName(expr.X, file, scope)
+	case *ast.ArrayType:
+		return "[]" + parser.typeArgName(expr.Elt, file, scope)
+	case *ast.MapType:
+		return "map[" + parser.typeArgName(expr.Key, file, scope) + "]" + parser.typeArgName(expr.Value, file, scope)
+	}
+
+	if genericType, args, ok := genericTypeArgs(expr); ok {
+		argNames := make([]string, 0, len(args))
+		for _, arg := range args {
+			argNames = append(argNames, parser.typeArgName(arg, file, scope))
+		}
+		return parser.typeArgName(genericType, file, scope) + "[" + strings.Join(argNames, ",") + "]"
+	}
+
+	typeName := types.ExprString(expr)
+	if IsGolangPrimitiveType(typeName) {
+		return typeName
+	}
+	if typeSpecDef := parser.packages.FindTypeSpec(typeName, file); typeSpecDef != nil {
+		return typeSpecDef.FullName()
+	}
+	return typeName
+}
+
+// genericDocName returns the name of an instantiated generic type in doc, like model.Page-model_User.
+func genericDocName(docName string, typeArgs []string) string {
+	replacer := strings.NewReplacer("[]", "array_", "map[", "map_", "[", "-", "]", "", ",", "-", ".", "_")
+	for _, arg := range typeArgs {
+		docName += "-" + replacer.Replace(arg)
+	}
+	return docName
+}
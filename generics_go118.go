@@ -0,0 +1,32 @@
+//go:build go1.18
+// +build go1.18
+
+package swag
+
+import "go/ast"
+
+// typeParamNames returns the names of the type parameters of a generic type definition.
+func typeParamNames(typeSpec *ast.TypeSpec) []string {
+	if typeSpec.TypeParams == nil {
+		return nil
+	}
+
+	var names []string
+	for _, field := range typeSpec.TypeParams.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// genericTypeArgs splits an instantiated generic type like Page[User] into the generic type and its type arguments.
+func genericTypeArgs(expr ast.Expr) (ast.Expr, []ast.Expr, bool) {
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		return expr.X, []ast.Expr{expr.Index}, true
+	case *ast.IndexListExpr:
+		return expr.X, expr.Indices, true
+	}
+	return nil, nil, false
+}
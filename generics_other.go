@@ -0,0 +1,18 @@
+//go:build !go1.18
+// +build !go1.18
+
+package swag
+
+import "go/ast"
+
+// typeParamNames returns the names of the type parameters of a generic type definition,
+// there are none before go1.18.
+func typeParamNames(typeSpec *ast.TypeSpec) []string {
+	return nil
+}
+
+// genericTypeArgs splits an instantiated generic type like Page[User] into the generic type and its type arguments,
+// there are none before go1.18.
+func genericTypeArgs(expr ast.Expr) (ast.Expr, []ast.Expr, bool) {
+	return nil, nil, false
+}
@@ -0,0 +1,293 @@
+//go:build go1.18
+// +build go1.18
+
+package swag
+
+import (
+	"encoding/json"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGenericTypes(t *testing.T) {
+	model := `
+package model
+
+type User struct {
+	Name string
+}
+
+type Page[T any] struct {
+	Items []T
+	Total int
+}
+
+type List[T any] struct {
+	Elements []T
+	First    *T
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+`
+	src := `
+package api
+
+import "github.com/example/model"
+
+type Users struct {
+	Pages map[string]model.Page[model.User]
+}
+
+// @Success 200 {object} model.Page[model.User]
+// @Router /users [get]
+func ListUsers(){
+}
+
+// @Success 200 {object} model.Page[model.List[model.User]]
+// @Router /lists [get]
+func ListLists(){
+}
+
+// @Success 200 {object} api.Users
+// @Router /pages [get]
+func ListPages(){
+}
+
+// @Success 200 {object} model.Pair[string,model.User]
+// @Router /pair [get]
+func GetPair(){
+}
+`
+	modelFile, err := goparser.ParseFile(token.NewFileSet(), "model.go", model, goparser.ParseComments)
+	assert.NoError(t, err)
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("github.com/example/model", "model/model.go", modelFile)
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	expected := `{
+   "api.Users": {
+      "type": "object",
+      "properties": {
+         "pages": {
+            "type": "object",
+            "additionalProperties": {
+               "$ref": "#/definitions/model.Page-model_User"
+            }
+         }
+      }
+   },
+   "model.List-model_User": {
+      "type": "object",
+      "properties": {
+         "elements": {
+            "type": "array",
+            "items": {
+               "$ref": "#/definitions/model.User"
+            }
+         },
+         "first": {
+            "allOf": [
+               {
+                  "$ref": "#/definitions/model.User"
+               }
+            ],
+            "x-nullable": true
+         }
+      }
+   },
+   "model.Page-model_List-model_User": {
+      "type": "object",
+      "properties": {
+         "items": {
+            "type": "array",
+            "items": {
+               "$ref": "#/definitions/model.List-model_User"
+            }
+         },
+         "total": {
+            "type": "integer"
+         }
+      }
+   },
+   "model.Page-model_User": {
+      "type": "object",
+      "properties": {
+         "items": {
+            "type": "array",
+            "items": {
+               "$ref": "#/definitions/model.User"
+            }
+         },
+         "total": {
+            "type": "integer"
+         }
+      }
+   },
+   "model.Pair-string-model_User": {
+      "type": "object",
+      "properties": {
+         "key": {
+            "type": "string"
+         },
+         "value": {
+            "$ref": "#/definitions/model.User"
+         }
+      }
+   },
+   "model.User": {
+      "type": "object",
+      "properties": {
+         "name": {
+            "type": "string"
+         }
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.Equal(t, expected, string(b))
+
+	ref := p.swagger.Paths.Paths["/lists"].Get.Responses.StatusCodeResponses[200].Schema.Ref
+	assert.Equal(t, "#/definitions/model.Page-model_List-model_User", ref.String())
+}
+
+func TestParseGenericAlias(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	Name string
+}
+
+type Page[T any] struct {
+	Items []T
+}
+
+type UserPage = Page[User]
+
+// @Success 200 {object} api.UserPage
+// @Router /users [get]
+func ListUsers(){
+}
+
+// @Success 200 {object} api.Page[api.User]
+// @Router /pages [get]
+func ListPages(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+
+	// the alias is the instantiation itself, not a definition of its own
+	assert.Equal(t, []string{"api.Page-api_User", "api.User"}, definitionNames(p.swagger.Definitions))
+	for _, path := range []string{"/users", "/pages"} {
+		ref := p.swagger.Paths.Paths[path].Get.Responses.StatusCodeResponses[200].Schema.Ref
+		assert.Equal(t, "#/definitions/api.Page-api_User", ref.String())
+	}
+}
+
+func TestParseGenericFieldTypes(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	Name string
+}
+
+type List[T any] struct {
+	Elements []T
+}
+
+type Team struct {
+	Members List[User]
+	Leads   *List[User]
+}
+
+// @Success 200 {object} api.Team
+// @Router /team [get]
+func GetTeam(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+
+	expected := `{
+   "type": "object",
+   "properties": {
+      "leads": {
+         "allOf": [
+            {
+               "$ref": "#/definitions/api.List-api_User"
+            }
+         ],
+         "x-nullable": true
+      },
+      "members": {
+         "$ref": "#/definitions/api.List-api_User"
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.Team"], "", "   ")
+	assert.Equal(t, expected, string(b))
+	assert.Contains(t, p.swagger.Definitions, "api.List-api_User")
+}
+
+func TestParseArrayOfGenericBodyParam(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	Name string
+}
+
+type Paged[T any] struct {
+	Items []T
+}
+
+// @Param body body []Paged[User] true "pages"
+// @Success 200 {array} api.Paged[api.User]
+// @Router /pages [post]
+func PostPages(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+
+	operation := p.swagger.Paths.Paths["/pages"].Post
+	for _, schema := range []*spec.Schema{operation.Parameters[0].Schema, operation.Responses.StatusCodeResponses[200].Schema} {
+		assert.Equal(t, spec.StringOrArray{ARRAY}, schema.Type)
+		assert.Equal(t, "#/definitions/api.Paged-api_User", schema.Items.Schema.Ref.String())
+	}
+}
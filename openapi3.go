@@ -0,0 +1,730 @@
+package swag
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// OpenAPI3Version the version of OpenAPI documents converted from swagger
+const OpenAPI3Version = "3.0.3"
+
+// OpenAPI31Version the version of OpenAPI 3.1 documents converted from swagger
+const OpenAPI31Version = "3.1.0"
+
+// OpenAPI3 an OpenAPI 3.0 document
+type OpenAPI3 struct {
+	OpenAPI      string                      `json:"openapi"`
+	Info         *OpenAPI3Info               `json:"info,omitempty"`
+	Servers      []OpenAPI3Server            `json:"servers,omitempty"`
+	Paths        map[string]OpenAPI3PathItem `json:"paths"`
+	Components   OpenAPI3Components          `json:"components,omitempty"`
+	Security     []map[string][]string       `json:"security,omitempty"`
+	Tags         []spec.Tag                  `json:"tags,omitempty"`
+	ExternalDocs *spec.ExternalDocumentation `json:"externalDocs,omitempty"`
+	Extensions   spec.Extensions             `json:"-"`
+}
+
+// OpenAPI3Info the info of a document, with the summary added by OpenAPI 3.1
+type OpenAPI3Info struct {
+	*spec.Info
+	Summary string `json:"-"`
+}
+
+// OpenAPI3Server a server hosting the API
+type OpenAPI3Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPI3PathItem the operations of a path, map key is the lower case http method
+type OpenAPI3PathItem map[string]*OpenAPI3Operation
+
+// OpenAPI3Components the reusable objects of a document
+type OpenAPI3Components struct {
+	Schemas         map[string]spec.Schema             `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*OpenAPI3SecurityScheme `json:"securitySchemes,omitempty"`
+	Examples        map[string]OpenAPI3Example         `json:"examples,omitempty"`
+}
+
+// OpenAPI3Example a named example, or a reference to one of components/examples
+type OpenAPI3Example struct {
+	Ref     string      `json:"$ref,omitempty"`
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+// OpenAPI3Operation an operation of a path
+type OpenAPI3Operation struct {
+	Tags         []string                    `json:"tags,omitempty"`
+	Summary      string                      `json:"summary,omitempty"`
+	Description  string                      `json:"description,omitempty"`
+	ExternalDocs *spec.ExternalDocumentation `json:"externalDocs,omitempty"`
+	OperationID  string                      `json:"operationId,omitempty"`
+	Parameters   []OpenAPI3Parameter         `json:"parameters,omitempty"`
+	RequestBody  *OpenAPI3RequestBody        `json:"requestBody,omitempty"`
+	Responses    map[string]OpenAPI3Response `json:"responses"`
+	Deprecated   bool                        `json:"deprecated,omitempty"`
+	Security     []map[string][]string       `json:"security,omitempty"`
+	Extensions   spec.Extensions             `json:"-"`
+}
+
+// OpenAPI3Parameter a path, query, header or cookie parameter of an operation
+type OpenAPI3Parameter struct {
+	Name        string                     `json:"name"`
+	In          string                     `json:"in"`
+	Description string                     `json:"description,omitempty"`
+	Required    bool                       `json:"required,omitempty"`
+	Style       string                     `json:"style,omitempty"`
+	Explode     *bool                      `json:"explode,omitempty"`
+	Schema      *spec.Schema               `json:"schema,omitempty"`
+	Example     interface{}                `json:"example,omitempty"`
+	Examples    map[string]OpenAPI3Example `json:"examples,omitempty"`
+	Extensions  spec.Extensions            `json:"-"`
+}
+
+// OpenAPI3RequestBody the request body of an operation
+type OpenAPI3RequestBody struct {
+	Description string                       `json:"description,omitempty"`
+	Content     map[string]OpenAPI3MediaType `json:"content"`
+	Required    bool                         `json:"required,omitempty"`
+}
+
+// OpenAPI3Response a response of an operation
+type OpenAPI3Response struct {
+	Description string                       `json:"description"`
+	Headers     map[string]OpenAPI3Header    `json:"headers,omitempty"`
+	Content     map[string]OpenAPI3MediaType `json:"content,omitempty"`
+	Links       map[string]OpenAPI3Link      `json:"links,omitempty"`
+}
+
+// OpenAPI3Link a link from a response to an operation, whose parameters are taken from the response
+type OpenAPI3Link struct {
+	OperationID string                 `json:"operationId,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	Description string                 `json:"description,omitempty"`
+}
+
+// OpenAPI3Header a header of a response
+type OpenAPI3Header struct {
+	Description string       `json:"description,omitempty"`
+	Schema      *spec.Schema `json:"schema,omitempty"`
+}
+
+// OpenAPI3MediaType the schema and example of a request or response body of a media type
+type OpenAPI3MediaType struct {
+	Schema   *spec.Schema               `json:"schema,omitempty"`
+	Example  interface{}                `json:"example,omitempty"`
+	Examples map[string]OpenAPI3Example `json:"examples,omitempty"`
+}
+
+// OpenAPI3SecurityScheme a security scheme of the API
+type OpenAPI3SecurityScheme struct {
+	Type         string               `json:"type"`
+	Description  string               `json:"description,omitempty"`
+	Name         string               `json:"name,omitempty"`
+	In           string               `json:"in,omitempty"`
+	Scheme       string               `json:"scheme,omitempty"`
+	BearerFormat string               `json:"bearerFormat,omitempty"`
+	Flows        map[string]OAuthFlow `json:"flows,omitempty"`
+	Extensions   spec.Extensions      `json:"-"`
+}
+
+// OAuthFlow an OAuth2 flow of a security scheme, map key of the flows is the kind of flow
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+// MarshalJSON marshals the document with its extensions
+func (o OpenAPI3) MarshalJSON() ([]byte, error) {
+	type document OpenAPI3
+	return marshalWithExtensions(document(o), o.Extensions)
+}
+
+// MarshalJSON marshals the info with its summary
+func (i OpenAPI3Info) MarshalJSON() ([]byte, error) {
+	if i.Info == nil || i.Summary == "" {
+		return json.Marshal(i.Info)
+	}
+	return marshalWithExtensions(i.Info, spec.Extensions{"summary": i.Summary})
+}
+
+// MarshalJSON marshals the operation with its extensions
+func (o OpenAPI3Operation) MarshalJSON() ([]byte, error) {
+	type operation OpenAPI3Operation
+	return marshalWithExtensions(operation(o), o.Extensions)
+}
+
+// MarshalJSON marshals the parameter with its extensions
+func (p OpenAPI3Parameter) MarshalJSON() ([]byte, error) {
+	type parameter OpenAPI3Parameter
+	return marshalWithExtensions(parameter(p), p.Extensions)
+}
+
+// MarshalJSON marshals the security scheme with its extensions
+func (s OpenAPI3SecurityScheme) MarshalJSON() ([]byte, error) {
+	type securityScheme OpenAPI3SecurityScheme
+	return marshalWithExtensions(securityScheme(s), s.Extensions)
+}
+
+// marshalWithExtensions marshals v, which has to be a json object, and adds extensions to it.
+func marshalWithExtensions(v interface{}, extensions spec.Extensions) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return b, err
+	}
+	ext, err := json.Marshal(extensions)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 2 { // {}
+		return ext, nil
+	}
+	return append(append(b[:len(b)-1], ','), ext[1:]...), nil
+}
+
+// oauthFlows maps the swagger 2.0 oauth2 flows to the OpenAPI 3.0 ones
+var oauthFlows = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+// ConvertToOpenAPI3 converts a swagger 2.0 document to an OpenAPI 3.0 document. Definitions become
+// components/schemas, body and formData params become request bodies and the bodies of requests and
+// responses get a content map keyed by media type. The x-nullable, x-writeOnly, x-style and x-explode
+// extensions are turned into their OpenAPI 3.0 keywords.
+func ConvertToOpenAPI3(swagger *spec.Swagger) *OpenAPI3 {
+	doc := &OpenAPI3{
+		OpenAPI:      OpenAPI3Version,
+		Servers:      openAPI3Servers(swagger.Schemes, swagger.Host, swagger.BasePath),
+		Paths:        make(map[string]OpenAPI3PathItem),
+		Security:     swagger.Security,
+		Tags:         swagger.Tags,
+		ExternalDocs: swagger.ExternalDocs,
+		Extensions:   swagger.Extensions,
+	}
+	if swagger.Info != nil {
+		doc.Info = &OpenAPI3Info{Info: swagger.Info}
+	}
+
+	if len(swagger.Definitions) > 0 {
+		doc.Components.Schemas = make(map[string]spec.Schema, len(swagger.Definitions))
+		for name, schema := range swagger.Definitions {
+			doc.Components.Schemas[name] = *convertSchema(&schema)
+		}
+	}
+
+	if examples, ok := swagger.Extensions["x-examples"]; ok {
+		// the examples may have been unmarshaled from a cached doc, so they're converted through json
+		if b, err := json.Marshal(examples); err == nil {
+			_ = json.Unmarshal(b, &doc.Components.Examples)
+		}
+		doc.Extensions = spec.Extensions{}
+		for key, value := range swagger.Extensions {
+			if key != "x-examples" {
+				doc.Extensions[key] = value
+			}
+		}
+	}
+
+	if len(swagger.SecurityDefinitions) > 0 {
+		doc.Components.SecuritySchemes = make(map[string]*OpenAPI3SecurityScheme, len(swagger.SecurityDefinitions))
+		for name, scheme := range swagger.SecurityDefinitions {
+			doc.Components.SecuritySchemes[name] = convertSecurityScheme(scheme)
+		}
+	}
+
+	if swagger.Paths == nil {
+		return doc
+	}
+	for path, pathItem := range swagger.Paths.Paths {
+		item := OpenAPI3PathItem{}
+		for method, operation := range map[string]*spec.Operation{
+			http.MethodGet:     pathItem.Get,
+			http.MethodPut:     pathItem.Put,
+			http.MethodPost:    pathItem.Post,
+			http.MethodDelete:  pathItem.Delete,
+			http.MethodOptions: pathItem.Options,
+			http.MethodHead:    pathItem.Head,
+			http.MethodPatch:   pathItem.Patch,
+		} {
+			if operation != nil {
+				item[strings.ToLower(method)] = convertOperation(operation, swagger.Consumes, swagger.Produces)
+			}
+		}
+		doc.Paths[path] = item
+	}
+
+	return doc
+}
+
+// ConvertToOpenAPI31 converts a swagger 2.0 document to an OpenAPI 3.1 document like ConvertToOpenAPI3.
+// The x-summary of the info, set by the general @summary annotation, becomes the summary of the info.
+// Schemas are kept as converted for OpenAPI 3.0.
+func ConvertToOpenAPI31(swagger *spec.Swagger) *OpenAPI3 {
+	doc := ConvertToOpenAPI3(swagger)
+	doc.OpenAPI = OpenAPI31Version
+	if doc.Info == nil {
+		return doc
+	}
+	summary, ok := doc.Info.Extensions["x-summary"].(string)
+	if !ok {
+		return doc
+	}
+
+	info := *doc.Info.Info
+	info.Extensions = spec.Extensions{}
+	for key, value := range doc.Info.Extensions {
+		if key != "x-summary" {
+			info.Extensions[key] = value
+		}
+	}
+	doc.Info = &OpenAPI3Info{Info: &info, Summary: summary}
+	return doc
+}
+
+// openAPI3Servers returns a server for each scheme of host and basePath.
+func openAPI3Servers(schemes []string, host, basePath string) []OpenAPI3Server {
+	if host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []OpenAPI3Server{{URL: basePath}}
+	}
+	if len(schemes) == 0 {
+		return []OpenAPI3Server{{URL: "//" + host + basePath}}
+	}
+
+	servers := make([]OpenAPI3Server, 0, len(schemes))
+	for _, scheme := range schemes {
+		servers = append(servers, OpenAPI3Server{URL: scheme + "://" + host + basePath})
+	}
+	return servers
+}
+
+func convertSecurityScheme(scheme *spec.SecurityScheme) *OpenAPI3SecurityScheme {
+	result := &OpenAPI3SecurityScheme{
+		Type:        scheme.Type,
+		Description: scheme.Description,
+		Extensions:  scheme.Extensions,
+	}
+
+	switch scheme.Type {
+	case "basic":
+		result.Type = "http"
+		result.Scheme = "basic"
+	case "apiKey":
+		if bearer, _ := scheme.Extensions.GetString("x-scheme"); bearer == "bearer" {
+			// an Authorization header standing in for the http bearer scheme of OpenAPI 3
+			result.Type = "http"
+			result.Scheme = "bearer"
+			result.BearerFormat, _ = scheme.Extensions.GetString("x-bearer-format")
+			result.Extensions = spec.Extensions{}
+			for key, value := range scheme.Extensions {
+				if key != "x-scheme" && key != "x-bearer-format" {
+					result.Extensions[key] = value
+				}
+			}
+			break
+		}
+		result.Name = scheme.Name
+		result.In = scheme.In
+	case "oauth2":
+		scopes := scheme.Scopes
+		if scopes == nil {
+			scopes = map[string]string{}
+		}
+		result.Flows = map[string]OAuthFlow{
+			oauthFlows[scheme.Flow]: {
+				AuthorizationURL: scheme.AuthorizationURL,
+				TokenURL:         scheme.TokenURL,
+				Scopes:           scopes,
+			},
+		}
+	}
+	return result
+}
+
+func convertOperation(operation *spec.Operation, consumes, produces []string) *OpenAPI3Operation {
+	result := &OpenAPI3Operation{
+		Tags:         operation.Tags,
+		Summary:      operation.Summary,
+		Description:  operation.Description,
+		ExternalDocs: operation.ExternalDocs,
+		OperationID:  operation.ID,
+		Responses:    make(map[string]OpenAPI3Response),
+		Deprecated:   operation.Deprecated,
+		Security:     operation.Security,
+		Extensions:   operation.Extensions,
+	}
+
+	if len(operation.Consumes) > 0 {
+		consumes = operation.Consumes
+	}
+	if len(operation.Produces) > 0 {
+		produces = operation.Produces
+	}
+
+	var formData []spec.Parameter
+	for _, param := range operation.Parameters {
+		switch param.In {
+		case "body":
+			result.RequestBody = &OpenAPI3RequestBody{
+				Description: param.Description,
+				Content:     convertContent(consumes, convertSchema(param.Schema), nil),
+				Required:    param.Required,
+			}
+			if ref, ok := param.Extensions.GetString("x-example-ref"); ok {
+				for mimeType, mediaType := range result.RequestBody.Content {
+					mediaType.Examples = exampleRefs([]string{ref})
+					result.RequestBody.Content[mimeType] = mediaType
+				}
+			}
+		case "formData":
+			formData = append(formData, param)
+		default:
+			result.Parameters = append(result.Parameters, convertParameter(param))
+		}
+	}
+	if len(formData) > 0 {
+		result.RequestBody = convertFormData(formData, consumes)
+	}
+
+	if operation.Responses != nil {
+		if operation.Responses.Default != nil {
+			result.Responses["default"] = convertResponse(operation.Responses.Default, produces)
+		}
+		for code, response := range operation.Responses.StatusCodeResponses {
+			response := response
+			result.Responses[strconv.Itoa(code)] = convertResponse(&response, produces)
+		}
+		convertStatusRanges(operation.Responses, result.Responses, produces)
+	}
+
+	return result
+}
+
+// convertStatusRanges replaces the responses a status range like 4XX was expanded to for swagger 2.0
+// by the range itself
+func convertStatusRanges(responses *spec.Responses, result map[string]OpenAPI3Response, produces []string) {
+	value, ok := responses.Extensions["x-status-ranges"]
+	if !ok {
+		return
+	}
+	// the ranges may have been unmarshaled from a cached doc, so they're converted through json
+	var ranges map[string]spec.Response
+	b, err := json.Marshal(value)
+	if err != nil || json.Unmarshal(b, &ranges) != nil {
+		return
+	}
+
+	for statusRange, rangeResponse := range ranges {
+		for _, code := range statusCodeRanges[statusRange] {
+			response, ok := responses.StatusCodeResponses[code]
+			if ok && isRangeResponse(response, rangeResponse, code) {
+				delete(result, strconv.Itoa(code))
+			}
+		}
+		if rangeResponse.Description == "" {
+			rangeResponse.Description = statusRangeTexts[statusRange]
+		}
+		result[statusRange] = convertResponse(&rangeResponse, produces)
+	}
+}
+
+// isRangeResponse reports whether the response of code was added by the status range, not documented explicitly
+func isRangeResponse(response, rangeResponse spec.Response, code int) bool {
+	if rangeResponse.Description == "" {
+		rangeResponse.Description = http.StatusText(code)
+	}
+	expected, err := json.Marshal(rangeResponse)
+	if err != nil {
+		return false
+	}
+	actual, err := json.Marshal(response)
+	return err == nil && bytes.Equal(expected, actual)
+}
+
+func convertParameter(param spec.Parameter) OpenAPI3Parameter {
+	result := OpenAPI3Parameter{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+		Example:     param.Example,
+		Extensions:  spec.Extensions{},
+	}
+
+	if param.Schema != nil {
+		result.Schema = convertSchema(param.Schema)
+	} else {
+		result.Schema = simpleSchema(param.SimpleSchema, param.CommonValidations)
+		result.Schema.Example = nil
+	}
+
+	switch param.CollectionFormat {
+	case "multi":
+		result.Style = "form"
+		result.Explode = boolPtr(true)
+	case "ssv":
+		result.Style = "spaceDelimited"
+	case "pipes":
+		result.Style = "pipeDelimited"
+	default:
+		// csv, the default of swagger 2.0, other than the one of OpenAPI 3.0 for queries
+		if param.Type == ARRAY && param.In == "query" {
+			result.Style = "form"
+			result.Explode = boolPtr(false)
+		}
+	}
+
+	for key, value := range param.Extensions {
+		switch key {
+		case "x-style":
+			if style, ok := value.(string); ok {
+				result.Style = style
+				continue
+			}
+		case "x-explode":
+			if explode, ok := value.(bool); ok {
+				result.Explode = boolPtr(explode)
+				continue
+			}
+		case "x-example-ref":
+			if ref, ok := value.(string); ok {
+				result.Examples = exampleRefs([]string{ref})
+				result.Example = nil
+				continue
+			}
+		}
+		result.Extensions[key] = value
+	}
+
+	return result
+}
+
+// convertFormData returns the request body of the formData params of an operation.
+func convertFormData(params []spec.Parameter, consumes []string) *OpenAPI3RequestBody {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Type:       []string{OBJECT},
+		Properties: make(map[string]spec.Schema),
+	}}
+	mimeType := "application/x-www-form-urlencoded"
+	for _, param := range params {
+		property := simpleSchema(param.SimpleSchema, param.CommonValidations)
+		property.Description = param.Description
+		if param.Type == "file" {
+			property.Type = []string{STRING}
+			property.Format = "binary"
+			mimeType = "multipart/form-data"
+		}
+		schema.Properties[param.Name] = *property
+		if param.Required {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+	sort.Strings(schema.Required)
+
+	for _, consume := range consumes {
+		if consume == "multipart/form-data" {
+			mimeType = consume
+		}
+	}
+
+	return &OpenAPI3RequestBody{
+		Content: map[string]OpenAPI3MediaType{mimeType: {Schema: schema}},
+	}
+}
+
+func convertResponse(response *spec.Response, produces []string) OpenAPI3Response {
+	result := OpenAPI3Response{Description: response.Description}
+
+	if len(response.Headers) > 0 {
+		result.Headers = make(map[string]OpenAPI3Header, len(response.Headers))
+		for name, header := range response.Headers {
+			result.Headers[name] = OpenAPI3Header{
+				Description: header.Description,
+				Schema:      simpleSchema(header.SimpleSchema, header.CommonValidations),
+			}
+		}
+	}
+
+	if response.Schema != nil {
+		result.Content = convertContent(produces, convertSchema(response.Schema), response.Examples)
+	}
+
+	var refs []string
+	if examples, ok := response.Extensions["x-example-refs"]; ok && result.Content != nil {
+		if b, err := json.Marshal(examples); err == nil {
+			_ = json.Unmarshal(b, &refs)
+		}
+		for mimeType, mediaType := range result.Content {
+			// example and examples are mutually exclusive
+			mediaType.Example = nil
+			mediaType.Examples = exampleRefs(refs)
+			result.Content[mimeType] = mediaType
+		}
+	}
+
+	if links, ok := response.Extensions["x-links"]; ok {
+		// the links may have been unmarshaled from a cached doc, so they're converted through json
+		if b, err := json.Marshal(links); err == nil {
+			_ = json.Unmarshal(b, &result.Links)
+		}
+	}
+
+	return result
+}
+
+// exampleRefs returns the references to the named examples of components/examples.
+func exampleRefs(names []string) map[string]OpenAPI3Example {
+	examples := make(map[string]OpenAPI3Example, len(names))
+	for _, name := range names {
+		examples[name] = OpenAPI3Example{Ref: "#/components/examples/" + name}
+	}
+	return examples
+}
+
+// convertContent returns the content of a body with schema for each mime type, application/json by default.
+func convertContent(mimeTypes []string, schema *spec.Schema, examples map[string]interface{}) map[string]OpenAPI3MediaType {
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{"application/json"}
+	}
+
+	content := make(map[string]OpenAPI3MediaType, len(mimeTypes))
+	for _, mimeType := range mimeTypes {
+		content[mimeType] = OpenAPI3MediaType{Schema: schema, Example: examples[mimeType]}
+	}
+	return content
+}
+
+// simpleSchema returns the schema of a parameter, item or header which isn't described by a schema in swagger 2.0.
+func simpleSchema(simple spec.SimpleSchema, validations spec.CommonValidations) *spec.Schema {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Format:           simple.Format,
+		Default:          simple.Default,
+		Maximum:          validations.Maximum,
+		ExclusiveMaximum: validations.ExclusiveMaximum,
+		Minimum:          validations.Minimum,
+		ExclusiveMinimum: validations.ExclusiveMinimum,
+		MaxLength:        validations.MaxLength,
+		MinLength:        validations.MinLength,
+		Pattern:          validations.Pattern,
+		MaxItems:         validations.MaxItems,
+		MinItems:         validations.MinItems,
+		UniqueItems:      validations.UniqueItems,
+		MultipleOf:       validations.MultipleOf,
+		Enum:             validations.Enum,
+	}}
+	schema.Example = simple.Example
+	if simple.Type != "" {
+		schema.Type = []string{simple.Type}
+	}
+	if simple.Items != nil {
+		schema.Items = &spec.SchemaOrArray{Schema: simpleSchema(simple.Items.SimpleSchema, simple.Items.CommonValidations)}
+	}
+	return schema
+}
+
+// schemaKeywords extensions of Swagger 2.0 schemas standing in for keywords of OpenAPI 3.0 schemas
+var schemaKeywords = map[string]string{
+	"x-nullable":  "nullable",
+	"x-writeOnly": "writeOnly",
+}
+
+// convertSchema returns a copy of schema with references to components/schemas and
+// x-nullable and x-writeOnly turned into nullable and writeOnly.
+func convertSchema(schema *spec.Schema) *spec.Schema {
+	if schema == nil {
+		return nil
+	}
+
+	result := *schema
+	if ref := schema.Ref.String(); strings.HasPrefix(ref, "#/definitions/") {
+		result.Ref = spec.MustCreateRef("#/components/schemas/" + strings.TrimPrefix(ref, "#/definitions/"))
+	}
+
+	keywords := make(map[string]interface{})
+	set := false
+	for extension, keyword := range schemaKeywords {
+		if value, ok := schema.Extensions[extension].(bool); ok {
+			keywords[keyword] = value
+			set = set || value
+		}
+	}
+	if len(keywords) > 0 {
+		result.Extensions = make(spec.Extensions, len(schema.Extensions))
+		for key, value := range schema.Extensions {
+			if _, ok := schemaKeywords[key]; !ok {
+				result.Extensions[key] = value
+			}
+		}
+		if len(result.Extensions) == 0 {
+			result.Extensions = nil
+		}
+		result.ExtraProps = make(map[string]interface{}, len(schema.ExtraProps)+len(keywords))
+		for key, value := range schema.ExtraProps {
+			result.ExtraProps[key] = value
+		}
+		for key, value := range keywords {
+			result.ExtraProps[key] = value
+		}
+		if set && result.Ref.String() != "" {
+			// siblings of $ref are ignored, so the reference is wrapped
+			wrapped := spec.Schema{SchemaProps: spec.SchemaProps{AllOf: []spec.Schema{*spec.RefSchema(result.Ref.String())}}}
+			result.Ref = spec.Ref{}
+			wrapped.Description = result.Description
+			wrapped.Extensions = result.Extensions
+			wrapped.ExtraProps = result.ExtraProps
+			return &wrapped
+		}
+	}
+
+	if len(schema.Properties) > 0 {
+		result.Properties = make(map[string]spec.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			result.Properties[name] = *convertSchema(&property)
+		}
+	}
+	if schema.Items != nil {
+		result.Items = &spec.SchemaOrArray{Schema: convertSchema(schema.Items.Schema)}
+		for _, item := range schema.Items.Schemas {
+			result.Items.Schemas = append(result.Items.Schemas, *convertSchema(&item))
+		}
+	}
+	if schema.AdditionalProperties != nil {
+		result.AdditionalProperties = &spec.SchemaOrBool{
+			Allows: schema.AdditionalProperties.Allows,
+			Schema: convertSchema(schema.AdditionalProperties.Schema),
+		}
+	}
+	result.AllOf = convertSchemas(schema.AllOf)
+	result.OneOf = convertSchemas(schema.OneOf)
+	result.AnyOf = convertSchemas(schema.AnyOf)
+
+	return &result
+}
+
+func convertSchemas(schemas []spec.Schema) []spec.Schema {
+	if schemas == nil {
+		return nil
+	}
+	result := make([]spec.Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		result = append(result, *convertSchema(&schema))
+	}
+	return result
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,527 @@
+package swag
+
+import (
+	"encoding/json"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertToOpenAPI3(t *testing.T) {
+	src := `
+package api
+
+type Pet struct {
+	Name  string
+	Owner *Owner
+	Age   *int
+}
+
+type Owner struct {
+	Name string
+}
+
+// @Summary Create a pet
+// @ID create-pet
+// @Accept json
+// @Produce json
+// @Param pet body api.Pet true "the pet"
+// @Param X-Request-ID header string false "request id"
+// @Param session cookie string true "session"
+// @Param filter query string false "filter" style(deepObject) explode(true)
+// @Param tags query []string false "tags"
+// @Success 201 {object} api.Pet "created"
+// @Header 201 {string} Location "location"
+// @Failure 400 {string} string "bad request"
+// @Router /pets [post]
+func CreatePet(){
+}
+
+// @Param file formData file true "photo"
+// @Param name formData string false "name"
+// @Success 204 "no content"
+// @Router /pets/photo [put]
+func UploadPhoto(){
+}
+`
+	p := New()
+	p.OpenAPI3 = true
+	parseTestSource(t, p, src)
+
+	p.swagger.Host = "example.com"
+	p.swagger.BasePath = "/v1"
+	p.swagger.Schemes = []string{"https"}
+	p.swagger.SecurityDefinitions = spec.SecurityDefinitions{
+		"basic":  spec.BasicAuth(),
+		"key":    spec.APIKeyAuth("X-API-Key", "header"),
+		"oauth2": spec.OAuth2Application("https://example.com/token"),
+	}
+
+	doc := ConvertToOpenAPI3(p.swagger)
+	b, err := json.MarshalIndent(doc, "", "    ")
+	assert.NoError(t, err)
+
+	expected := `{
+    "openapi": "3.0.3",
+    "info": {
+        "contact": {}
+    },
+    "servers": [
+        {
+            "url": "https://example.com/v1"
+        }
+    ],
+    "paths": {
+        "/pets": {
+            "post": {
+                "summary": "Create a pet",
+                "operationId": "create-pet",
+                "parameters": [
+                    {
+                        "name": "X-Request-ID",
+                        "in": "header",
+                        "description": "request id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "name": "session",
+                        "in": "cookie",
+                        "description": "session",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "name": "filter",
+                        "in": "query",
+                        "description": "filter",
+                        "style": "deepObject",
+                        "explode": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "name": "tags",
+                        "in": "query",
+                        "description": "tags",
+                        "style": "form",
+                        "explode": false,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "requestBody": {
+                    "description": "the pet",
+                    "content": {
+                        "application/json": {
+                            "schema": {
+                                "$ref": "#/components/schemas/api.Pet"
+                            }
+                        }
+                    },
+                    "required": true
+                },
+                "responses": {
+                    "201": {
+                        "description": "created",
+                        "headers": {
+                            "Location": {
+                                "description": "location",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        },
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/api.Pet"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "bad request",
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pets/photo": {
+            "put": {
+                "requestBody": {
+                    "content": {
+                        "multipart/form-data": {
+                            "schema": {
+                                "type": "object",
+                                "required": [
+                                    "file"
+                                ],
+                                "properties": {
+                                    "file": {
+                                        "description": "photo",
+                                        "type": "string",
+                                        "format": "binary"
+                                    },
+                                    "name": {
+                                        "description": "name",
+                                        "type": "string"
+                                    }
+                                }
+                            }
+                        }
+                    }
+                },
+                "responses": {
+                    "204": {
+                        "description": "no content"
+                    }
+                }
+            }
+        }
+    },
+    "components": {
+        "schemas": {
+            "api.Owner": {
+                "type": "object",
+                "properties": {
+                    "name": {
+                        "type": "string"
+                    }
+                }
+            },
+            "api.Pet": {
+                "type": "object",
+                "properties": {
+                    "age": {
+                        "type": "integer",
+                        "nullable": true
+                    },
+                    "name": {
+                        "type": "string"
+                    },
+                    "owner": {
+                        "allOf": [
+                            {
+                                "$ref": "#/components/schemas/api.Owner"
+                            }
+                        ],
+                        "nullable": true
+                    }
+                }
+            }
+        },
+        "securitySchemes": {
+            "basic": {
+                "type": "http",
+                "scheme": "basic"
+            },
+            "key": {
+                "type": "apiKey",
+                "name": "X-API-Key",
+                "in": "header"
+            },
+            "oauth2": {
+                "type": "oauth2",
+                "flows": {
+                    "clientCredentials": {
+                        "tokenUrl": "https://example.com/token",
+                        "scopes": {}
+                    }
+                }
+            }
+        }
+    }
+}`
+	assert.Equal(t, expected, string(b))
+
+	// the swagger 2.0 definitions keep their references, wrapped as they are nullable
+	owner := p.swagger.Definitions["api.Pet"].Properties["owner"]
+	assert.Equal(t, "#/definitions/api.Owner", owner.AllOf[0].Ref.String())
+}
+
+func TestConvertToOpenAPI31(t *testing.T) {
+	src := `
+// @title Pet Store
+// @summary Manages the pets of a shop
+// @version 1.0
+// @x-logo {"url": "logo.png"}
+package api
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "doc.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("api", "api/doc.go", f)
+	assert.NoError(t, p.parseGeneralAPIInfoFromPackageDocs(".", "main.go"))
+
+	b, err := json.Marshal(ConvertToOpenAPI31(p.swagger))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"openapi":"3.1.0","info":{"title":"Pet Store","contact":{},"version":"1.0",`+
+		`"x-logo":{"url":"logo.png"},"summary":"Manages the pets of a shop"},"paths":{},"components":{}}`, string(b))
+
+	// OpenAPI 3.0 has no info.summary
+	b, err = json.Marshal(ConvertToOpenAPI3(p.swagger).Info)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"title":"Pet Store","contact":{},"version":"1.0","x-logo":{"url":"logo.png"},`+
+		`"x-summary":"Manages the pets of a shop"}`, string(b))
+}
+
+func TestConvertToOpenAPI3Bearer(t *testing.T) {
+	src := `
+// @securityDefinitions.bearer BearerAuth
+// @bearerFormat JWT
+// @securityDefinitions.bearer TokenAuth
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+package api
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "doc.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	assert.NoError(t, p.parseGeneralAPIComment(f.Doc))
+
+	// swagger 2.0 has no bearer scheme
+	bearer := p.swagger.SecurityDefinitions["BearerAuth"]
+	assert.Equal(t, "apiKey", bearer.Type)
+	assert.Equal(t, "Authorization", bearer.Name)
+	assert.Equal(t, "header", bearer.In)
+
+	doc := ConvertToOpenAPI3(p.swagger)
+	b, err := json.MarshalIndent(doc.Components.SecuritySchemes, "", "    ")
+	assert.NoError(t, err)
+
+	expected := `{
+    "ApiKeyAuth": {
+        "type": "apiKey",
+        "name": "X-API-Key",
+        "in": "header"
+    },
+    "BearerAuth": {
+        "type": "http",
+        "scheme": "bearer",
+        "bearerFormat": "JWT"
+    },
+    "TokenAuth": {
+        "type": "http",
+        "scheme": "bearer"
+    }
+}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestConvertToOpenAPI3Links(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	ID int
+}
+
+// @Success 201 {object} api.User "created"
+// @Link 201 createdUser GetUser "id=$response.body#/ID"
+// @Router /users [post]
+func CreateUser(){
+}
+`
+	p := New()
+	p.OpenAPI3 = true
+	parseTestSource(t, p, src)
+
+	doc := ConvertToOpenAPI3(p.swagger)
+	b, err := json.MarshalIndent(doc.Paths["/users"]["post"].Responses["201"].Links, "", "    ")
+	assert.NoError(t, err)
+
+	expected := `{
+    "createdUser": {
+        "operationId": "GetUser",
+        "parameters": {
+            "id": "$response.body#/ID"
+        }
+    }
+}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestConvertToOpenAPI3RequestBody(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	ID int
+}
+
+// @Param user body api.User true "the user to create"
+// @Success 201 {object} api.User
+// @Router /users [post]
+func CreateUser(){
+}
+`
+	p := New()
+	p.OpenAPI3 = true
+	parseTestSource(t, p, src)
+
+	doc := ConvertToOpenAPI3(p.swagger)
+	b, err := json.MarshalIndent(doc.Paths["/users"]["post"].RequestBody, "", "    ")
+	assert.NoError(t, err)
+
+	expected := `{
+    "description": "the user to create",
+    "content": {
+        "application/json": {
+            "schema": {
+                "$ref": "#/components/schemas/api.User"
+            }
+        }
+    },
+    "required": true
+}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestConvertToOpenAPI3StatusRanges(t *testing.T) {
+	src := `
+package api
+
+type Error struct {
+	Message string
+}
+
+// @Failure 404 {string} string "not found"
+// @Failure 4XX {object} api.Error "client error"
+// @Failure 5XX
+// @Router /users [get]
+func GetUsers(){
+}
+`
+	p := New()
+	p.OpenAPI3 = true
+	parseTestSource(t, p, src)
+
+	// swagger 2.0 still lists single codes
+	assert.Contains(t, p.swagger.Paths.Paths["/users"].Get.Responses.StatusCodeResponses, 400)
+
+	doc := ConvertToOpenAPI3(p.swagger)
+	b, err := json.MarshalIndent(doc.Paths["/users"]["get"].Responses, "", "    ")
+	assert.NoError(t, err)
+
+	expected := `{
+    "404": {
+        "description": "not found",
+        "content": {
+            "application/json": {
+                "schema": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "4XX": {
+        "description": "client error",
+        "content": {
+            "application/json": {
+                "schema": {
+                    "$ref": "#/components/schemas/api.Error"
+                }
+            }
+        }
+    },
+    "5XX": {
+        "description": "Server Error"
+    }
+}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestConvertToOpenAPI3NamedExamples(t *testing.T) {
+	src := `
+// @example Rex {"id":1,"name":"Rex"}
+// @example.summary A dog
+package api
+
+type Pet struct {
+	ID   int
+	Name string
+}
+
+// @Param id path int true "id" exampleRef(RexID)
+// @Success 200 {object} api.Pet
+// @ExampleRef 200 Rex
+// @Router /pets/{id} [get]
+func GetPet(){
+}
+
+// @Param pet body api.Pet true "pet" exampleRef(Rex)
+// @Success 200,201 {object} api.Pet
+// @ExampleRef 200,201 Rex
+// @Router /pets [put]
+func PutPet(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.OpenAPI3 = true
+	assert.NoError(t, p.parseGeneralAPIComment(f.Doc))
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	// params can only refer to declared examples
+	err = p.ParseRouterAPIInfo("", f)
+	assert.EqualError(t, err, `ParseComment error in file  :example RexID isn't declared by @example. comment=id path int true "id" exampleRef(RexID)`)
+
+	name, example, err := parseNamedExample(`RexID 1`)
+	assert.NoError(t, err)
+	p.namedExamples()[name] = example
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+
+	doc := ConvertToOpenAPI3(p.swagger)
+	assert.NotContains(t, doc.Extensions, "x-examples")
+
+	b, err := json.MarshalIndent(doc.Components.Examples, "", "    ")
+	assert.NoError(t, err)
+	expected := `{
+    "Rex": {
+        "summary": "A dog",
+        "value": {
+            "id": 1,
+            "name": "Rex"
+        }
+    },
+    "RexID": {
+        "value": 1
+    }
+}`
+	assert.Equal(t, expected, string(b))
+
+	ref := map[string]OpenAPI3Example{"Rex": {Ref: "#/components/examples/Rex"}}
+	assert.Equal(t, ref, doc.Paths["/pets/{id}"]["get"].Responses["200"].Content["application/json"].Examples)
+	assert.Equal(t, ref, doc.Paths["/pets"]["put"].Responses["200"].Content["application/json"].Examples)
+	assert.Equal(t, ref, doc.Paths["/pets"]["put"].Responses["201"].Content["application/json"].Examples)
+	assert.Equal(t, ref, doc.Paths["/pets"]["put"].RequestBody.Content["application/json"].Examples)
+
+	param := doc.Paths["/pets/{id}"]["get"].Parameters[0]
+	assert.Equal(t, map[string]OpenAPI3Example{"RexID": {Ref: "#/components/examples/RexID"}}, param.Examples)
+	assert.NotContains(t, param.Extensions, "x-example-ref")
+}
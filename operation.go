@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,11 +19,18 @@ import (
 	"golang.org/x/tools/go/loader"
 )
 
+// RouteProperties describes the path and http method of a single @Router annotation.
+type RouteProperties struct {
+	HTTPMethod string
+	Path       string
+}
+
 // Operation describes a single API operation on a path.
 // For more information: https://github.com/Nerzal/swag#api-operation
 type Operation struct {
-	HTTPMethod string
-	Path       string
+	HTTPMethod       string
+	Path             string
+	RouterProperties []RouteProperties
 	spec.Operation
 
 	parser              *Parser
@@ -115,6 +123,10 @@ func (operation *Operation) ParseComment(comment string, astFile *ast.File) erro
 		err = operation.ParseResponseComment(lineRemainder, astFile)
 	case "@header":
 		err = operation.ParseResponseHeaderComment(lineRemainder, astFile)
+	case "@link":
+		err = operation.ParseResponseLinkComment(lineRemainder)
+	case "@exampleref":
+		err = operation.ParseResponseExampleComment(lineRemainder)
 	case "@router":
 		err = operation.ParseRouterComment(lineRemainder)
 	case "@security":
@@ -208,10 +220,15 @@ func (operation *Operation) ParseParamComment(commentLine string, astFile *ast.F
 	required := requiredText == "true" || requiredText == "required"
 	description := matches[5]
 
+	if paramType == "cookie" && !operation.parser.OpenAPI3 {
+		// swagger 2.0 has no cookie params
+		return fmt.Errorf("%s is not supported paramType without OpenAPI 3", paramType)
+	}
+
 	param := createParameter(paramType, description, name, refType, required)
 
 	switch paramType {
-	case "path", "header":
+	case "path", "header", "cookie":
 		switch objectType {
 		case ARRAY, OBJECT:
 			return fmt.Errorf("%s is not supported type for %s", refType, paramType)
@@ -232,6 +249,29 @@ func (operation *Operation) ParseParamComment(commentLine string, astFile *ast.F
 				},
 			}
 		case OBJECT:
+			expanded, err := isExpandedParam(commentLine)
+			if err != nil {
+				return err
+			}
+			if !expanded {
+				// keep the struct as a single parameter instead of expanding its fields
+				if !operation.parser.OpenAPI3 {
+					// swagger 2.0 has no schema for query params
+					return fmt.Errorf("%s is kept as a single %s param without OpenAPI 3. comment=%s",
+						refType, paramType, commentLine)
+				}
+				schema, err := operation.parser.getTypeSchema(refType, astFile, true)
+				if err != nil {
+					return err
+				}
+				// the type lives in the schema
+				param.SimpleSchema.Type = ""
+				param.Schema = schema
+				if !isDeepObjectParam(commentLine) {
+					param.AddExtension("x-style", "deepObject")
+				}
+				break
+			}
 			schema, err := operation.parser.getTypeSchema(refType, astFile, false)
 			if err != nil {
 				return err
@@ -250,7 +290,8 @@ func (operation *Operation) ParseParamComment(commentLine string, astFile *ast.F
 			items := schema.Properties.ToOrderedSchemaItems()
 			for _, item := range items {
 				name := item.Name
-				prop := item.Schema
+				// enum types may be referenced
+				prop := operation.parser.derefSchema(item.Schema)
 				if len(prop.Type) == 0 {
 					continue
 				}
@@ -305,7 +346,7 @@ func (operation *Operation) ParseParamComment(commentLine string, astFile *ast.F
 		return fmt.Errorf("%s is not supported paramType", paramType)
 	}
 
-	if err := operation.parseAndExtractionParamAttribute(commentLine, objectType, refType, &param); err != nil {
+	if err := operation.parseAndExtractionParamAttribute(commentLine, objectType, refType, &param, astFile); err != nil {
 		return err
 	}
 	operation.Operation.Parameters = append(operation.Operation.Parameters, param)
@@ -329,9 +370,41 @@ var regexAttributes = map[string]*regexp.Regexp{
 	"format": regexp.MustCompile(`(?i)\s+format\(.*\)`),
 	// for collectionFormat(csv)
 	"collectionFormat": regexp.MustCompile(`(?i)\s+collectionFormat\(.*\)`),
+	// for style(deepObject)
+	"style": regexp.MustCompile(`(?i)\s+style\(.*\)`),
+	// for explode(true)
+	"explode": regexp.MustCompile(`(?i)\s+explode\(.*\)`),
+	// for x-internal(true)
+	"x-internal": regexp.MustCompile(`(?i)\s+x-internal\(.*\)`),
+	// for example(5) or example({"id":1})
+	"example": regexp.MustCompile(`(?i)\s+example\(.*\)`),
+	// for exampleRef(Pet)
+	"exampleref": regexp.MustCompile(`(?i)\s+exampleRef\(.*\)`),
+	// for expand(false)
+	"expand": regexp.MustCompile(`(?i)\s+expand\(.*\)`),
+}
+
+// isDeepObjectParam reports whether the param comment asks for style(deepObject) serialization
+func isDeepObjectParam(commentLine string) bool {
+	style, err := findAttr(regexAttributes["style"], commentLine)
+	return err == nil && strings.EqualFold(style, "deepObject")
+}
+
+// isExpandedParam reports whether the fields of a struct query param become params of their own. That's the
+// default, unless expand(false) or style(deepObject) keeps the struct as a single param.
+func isExpandedParam(commentLine string) (bool, error) {
+	expand, err := findAttr(regexAttributes["expand"], commentLine)
+	if err != nil {
+		return !isDeepObjectParam(commentLine), nil
+	}
+	expanded, err := strconv.ParseBool(expand)
+	if err != nil {
+		return false, fmt.Errorf("expand is allow only a boolean. comment=%s got=%s", commentLine, expand)
+	}
+	return expanded, nil
 }
 
-func (operation *Operation) parseAndExtractionParamAttribute(commentLine, objectType, schemaType string, param *spec.Parameter) error {
+func (operation *Operation) parseAndExtractionParamAttribute(commentLine, objectType, schemaType string, param *spec.Parameter, astFile *ast.File) error {
 	schemaType = TransToValidSchemeType(schemaType)
 	for attrKey, re := range regexAttributes {
 		attr, err := findAttr(re, commentLine)
@@ -340,7 +413,7 @@ func (operation *Operation) parseAndExtractionParamAttribute(commentLine, object
 		}
 		switch attrKey {
 		case "enums":
-			err := setEnumParam(attr, schemaType, param)
+			err := setEnumParam(attr, objectType, schemaType, param)
 			if err != nil {
 				return err
 			}
@@ -357,6 +430,20 @@ func (operation *Operation) parseAndExtractionParamAttribute(commentLine, object
 			}
 			param.Minimum = &n
 		case "default":
+			if strings.HasPrefix(attr, "@") {
+				// default(@DefaultLimit) takes the value of a Go const
+				constValue, ok := operation.parser.packages.FindConstValue(attr[1:], astFile)
+				if !ok {
+					return fmt.Errorf("can not find const %s for default. comment=%s", attr[1:], commentLine)
+				}
+				value, err := defineType(schemaType, constValue)
+				if err != nil {
+					return fmt.Errorf("const %s of default doesn't match type %s: %s. comment=%s",
+						attr[1:], schemaType, constValue, commentLine)
+				}
+				param.Default = value
+				break
+			}
 			value, err := defineType(schemaType, attr)
 			if err != nil {
 				return nil
@@ -382,11 +469,119 @@ func (operation *Operation) parseAndExtractionParamAttribute(commentLine, object
 				return err
 			}
 			param.CollectionFormat = n
+		case "style":
+			param.AddExtension("x-style", attr)
+		case "explode":
+			explode, err := strconv.ParseBool(attr)
+			if err != nil {
+				return fmt.Errorf("explode is allow only a boolean. comment=%s got=%s", commentLine, attr)
+			}
+			param.AddExtension("x-explode", explode)
+		case "x-internal":
+			internal, err := strconv.ParseBool(attr)
+			if err != nil {
+				return fmt.Errorf("x-internal is allow only a boolean. comment=%s got=%s", commentLine, attr)
+			}
+			if internal {
+				param.AddExtension("x-internal", true)
+			}
+		case "example":
+			if param.In == "body" && param.Schema != nil {
+				value, err := findJSONAttr(regexAttributes["example"], commentLine)
+				if err != nil {
+					return fmt.Errorf("example is allow only a json value. comment=%s", commentLine)
+				}
+				if param.Schema.Ref.String() != "" {
+					// the siblings of $ref are ignored, so the referenced schema is wrapped
+					param.Schema = &spec.Schema{SchemaProps: spec.SchemaProps{
+						AllOf: []spec.Schema{{SchemaProps: spec.SchemaProps{Ref: param.Schema.Ref}}},
+					}}
+				}
+				param.Schema.Example = value
+				break
+			}
+			if objectType == ARRAY {
+				value, err := defineArrayExample(schemaType, attr, commentLine)
+				if err != nil {
+					return err
+				}
+				param.Example = value
+				break
+			}
+			value, err := defineType(schemaType, attr)
+			if err != nil {
+				return err
+			}
+			param.Example = value
+		case "exampleref":
+			if err := operation.checkNamedExample(attr, commentLine); err != nil {
+				return err
+			}
+			param.AddExtension("x-example-ref", attr)
 		}
 	}
+	return validateParamAttributes(param, commentLine)
+}
+
+// validateParamAttributes reports attributes of a param contradicting each other, like enums with a minimum,
+// which fix the allowed values twice, or a minimum above the maximum.
+func validateParamAttributes(param *spec.Parameter, commentLine string) error {
+	enums := param.Enum
+	if param.Items != nil && len(param.Items.Enum) > 0 {
+		enums = param.Items.Enum
+	}
+	if len(enums) > 0 {
+		bounds := []struct {
+			name string
+			set  bool
+		}{
+			{"maximum", param.Maximum != nil},
+			{"minimum", param.Minimum != nil},
+			{"maxlength", param.MaxLength != nil},
+			{"minlength", param.MinLength != nil},
+		}
+		for _, bound := range bounds {
+			if bound.set {
+				return fmt.Errorf("enums and %s are mutually exclusive. comment=%s", bound.name, commentLine)
+			}
+		}
+		if param.Default != nil && !containsValue(enums, param.Default) {
+			return fmt.Errorf("default %v is not one of the enums. comment=%s", param.Default, commentLine)
+		}
+	}
+	if param.Minimum != nil && param.Maximum != nil && *param.Minimum > *param.Maximum {
+		return fmt.Errorf("minimum %v is greater than maximum %v. comment=%s", *param.Minimum, *param.Maximum, commentLine)
+	}
+	if param.MinLength != nil && param.MaxLength != nil && *param.MinLength > *param.MaxLength {
+		return fmt.Errorf("minlength %d is greater than maxlength %d. comment=%s", *param.MinLength, *param.MaxLength, commentLine)
+	}
 	return nil
 }
 
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// findJSONAttr returns the json value of an attribute like example({"id":1}),
+// which may contain parentheses itself.
+func findJSONAttr(re *regexp.Regexp, commentLine string) (interface{}, error) {
+	loc := re.FindStringIndex(commentLine)
+	if loc == nil {
+		return nil, fmt.Errorf("can not find regex=%s, comment=%s", re.String(), commentLine)
+	}
+	attr := commentLine[loc[0]:]
+	var value interface{}
+	if err := json.NewDecoder(strings.NewReader(attr[strings.Index(attr, "(")+1:])).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
 func findAttr(re *regexp.Regexp, commentLine string) (string, error) {
 	attr := re.FindString(commentLine)
 	l := strings.Index(attr, "(")
@@ -419,7 +614,7 @@ func setNumberParam(name, schemaType, attr, commentLine string) (float64, error)
 	return n, nil
 }
 
-func setEnumParam(attr, schemaType string, param *spec.Parameter) error {
+func setEnumParam(attr, objectType, schemaType string, param *spec.Parameter) error {
 	for _, e := range strings.Split(attr, ",") {
 		e = strings.TrimSpace(e)
 
@@ -427,6 +622,11 @@ func setEnumParam(attr, schemaType string, param *spec.Parameter) error {
 		if err != nil {
 			return err
 		}
+		// the enum of an array param restricts its items, not the array itself
+		if objectType == ARRAY && param.Items != nil {
+			param.Items.Enum = append(param.Items.Enum, value)
+			continue
+		}
 		param.Enum = append(param.Enum, value)
 	}
 	return nil
@@ -468,6 +668,47 @@ func defineType(schemaType string, value string) (interface{}, error) {
 	}
 }
 
+// defineArrayExample returns the example of an array param, written as a json array like example(["a","b"])
+// or as comma separated values like example(a,b). Its items must be of the item type of the array.
+func defineArrayExample(itemType, attr, commentLine string) ([]interface{}, error) {
+	if !strings.HasPrefix(attr, "[") {
+		var items []interface{}
+		for _, e := range strings.Split(attr, ",") {
+			item, err := defineType(itemType, strings.TrimSpace(e))
+			if err != nil {
+				return nil, fmt.Errorf("example of an array has an item which isn't a %s. comment=%s", itemType, commentLine)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	value, err := findJSONAttr(regexAttributes["example"], commentLine)
+	items, ok := value.([]interface{})
+	if err != nil || !ok {
+		return nil, fmt.Errorf("example of an array is allow only a json array or comma separated values. comment=%s", commentLine)
+	}
+	for i, item := range items {
+		valid := false
+		switch v := item.(type) {
+		case string:
+			valid = itemType == STRING
+		case bool:
+			valid = itemType == BOOLEAN
+		case float64:
+			if itemType == INTEGER && v == float64(int(v)) {
+				items[i] = int(v)
+				valid = true
+			}
+			valid = valid || itemType == NUMBER
+		}
+		if !valid {
+			return nil, fmt.Errorf("example of an array has an item %v which isn't a %s. comment=%s", item, itemType, commentLine)
+		}
+	}
+	return items, nil
+}
+
 // ParseTagsComment parses comment for given `tag` comment string.
 func (operation *Operation) ParseTagsComment(commentLine string) {
 	tags := strings.Split(commentLine, ",")
@@ -515,10 +756,17 @@ func (operation *Operation) ParseRouterComment(commentLine string) error {
 		return fmt.Errorf("can not parse router comment \"%s\"", commentLine)
 	}
 	path := matches[1]
-	httpMethod := matches[2]
+	httpMethod := strings.ToUpper(matches[2])
 
-	operation.Path = path
-	operation.HTTPMethod = strings.ToUpper(httpMethod)
+	// Path and HTTPMethod keep the first router of an operation with several @Router annotations
+	if len(operation.RouterProperties) == 0 {
+		operation.Path = path
+		operation.HTTPMethod = httpMethod
+	}
+	operation.RouterProperties = append(operation.RouterProperties, RouteProperties{
+		HTTPMethod: httpMethod,
+		Path:       path,
+	})
 
 	return nil
 }
@@ -604,20 +852,28 @@ func findTypeDef(importPath, typeName string) (*ast.TypeSpec, error) {
 	return nil, fmt.Errorf("type spec not found")
 }
 
-var responsePattern = regexp.MustCompile(`^([\w,]+)[\s]+([\w\{\}]+)[\s]+([\w\-\.\/\{\}=,\[\]]+)[^"]*(.*)?`)
+var responsePattern = regexp.MustCompile(`^([\w,]+)[\s]+([\w\{\}]+)[\s]+([\w\-\.\/\{\}=,\[\]\*]+)[^"]*(.*)?`)
 
 //ResponseType{data1=Type1,data2=Type2}
 var combinedPattern = regexp.MustCompile(`^([\w\-\.\/\[\]]+)\{(.*)\}$`)
 
 func (operation *Operation) parseObjectSchema(refType string, astFile *ast.File) (*spec.Schema, error) {
+	// a pointer is marshaled like the value it points to, so *User refers to the definition of User
+	refType = strings.TrimLeft(refType, "*")
+
 	switch {
-	case refType == "interface{}":
+	case refType == "interface{}" || refType == "any":
 		return PrimitiveSchema(OBJECT), nil
 	case IsGolangPrimitiveType(refType):
 		refType = TransToValidSchemeType(refType)
 		return PrimitiveSchema(refType), nil
 	case IsPrimitiveType(refType):
 		return PrimitiveSchema(refType), nil
+	case isByteSlice(refType):
+		// encoding/json marshals []byte as a base64 string
+		schema := PrimitiveSchema(STRING)
+		schema.Format = "byte"
+		return schema, nil
 	case strings.HasPrefix(refType, "[]"):
 		schema, err := operation.parseObjectSchema(refType[2:], astFile)
 		if err != nil {
@@ -625,15 +881,14 @@ func (operation *Operation) parseObjectSchema(refType string, astFile *ast.File)
 		}
 		return spec.ArrayProperty(schema), nil
 	case strings.HasPrefix(refType, "map["):
-		//ignore key type
-		idx := strings.Index(refType, "]")
+		// ignore the key type, json object keys are strings whatever it is
+		idx := mapKeyEnd(refType)
 		if idx < 0 {
 			return nil, fmt.Errorf("invalid type: %s", refType)
 		}
 		refType = refType[idx+1:]
-		if refType == "interface{}" {
+		if refType == "interface{}" || refType == "any" {
 			return spec.MapProperty(nil), nil
-
 		}
 		schema, err := operation.parseObjectSchema(refType, astFile)
 		if err != nil {
@@ -655,6 +910,23 @@ func (operation *Operation) parseObjectSchema(refType string, astFile *ast.File)
 	}
 }
 
+// mapKeyEnd returns the index of the bracket closing the key type of a map type like map[[2]int]User, or -1.
+func mapKeyEnd(mapType string) int {
+	depth := 0
+	for i := len("map"); i < len(mapType); i++ {
+		switch mapType[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 func (operation *Operation) parseCombinedObjectSchema(refType string, astFile *ast.File) (*spec.Schema, error) {
 	matches := combinedPattern.FindStringSubmatch(refType)
 	if len(matches) != 3 {
@@ -695,18 +967,27 @@ func (operation *Operation) parseCombinedObjectSchema(refType string, astFile *a
 	if len(props) == 0 {
 		return schema, nil
 	}
-	return spec.ComposedSchema(*schema, spec.Schema{
+	inlineSchema := spec.Schema{
 		SchemaProps: spec.SchemaProps{
 			Type:       []string{OBJECT},
 			Properties: props,
 		},
-	}), nil
+	}
+	if refType == OBJECT {
+		// object{id=int} is an inline object, there's nothing to compose it with
+		return &inlineSchema, nil
+	}
+	return spec.ComposedSchema(*schema, inlineSchema), nil
+}
+
+func isByteSlice(refType string) bool {
+	return refType == "[]byte" || refType == "[]uint8"
 }
 
 func (operation *Operation) parseAPIObjectSchema(schemaType, refType string, astFile *ast.File) (*spec.Schema, error) {
 	switch schemaType {
 	case OBJECT:
-		if !strings.HasPrefix(refType, "[]") {
+		if !strings.HasPrefix(refType, "[]") || isByteSlice(refType) {
 			return operation.parseObjectSchema(refType, astFile)
 		}
 		refType = refType[2:]
@@ -756,6 +1037,8 @@ func (operation *Operation) ParseResponseComment(commentLine string, astFile *as
 				resp.Description = http.StatusText(code)
 			}
 			operation.AddResponse(code, resp)
+		} else if _, ok := statusCodeRanges[strings.ToUpper(codeStr)]; ok {
+			operation.addRangeResponse(strings.ToUpper(codeStr), schema, responseDescription)
 		} else {
 			return fmt.Errorf("can not parse response comment \"%s\"", commentLine)
 		}
@@ -825,6 +1108,103 @@ func (operation *Operation) ParseResponseHeaderComment(commentLine string, astFi
 	return nil
 }
 
+var linkPattern = regexp.MustCompile(`^([\w,]+)[\s]+([\w\-\.]+)[\s]+(\S+)(?:[\s]+"([^"]*)")?(?:[\s]+"([^"]*)")?$`)
+
+// ParseResponseLinkComment parses comment for given `response link` comment string,
+// like @Link 201 createdUser GetUser "id=$response.body#/id" "the created user".
+// The links are kept in the x-links extension of the responses, which becomes the links of OpenAPI 3 docs.
+func (operation *Operation) ParseResponseLinkComment(commentLine string) error {
+	matches := linkPattern.FindStringSubmatch(commentLine)
+	if len(matches) != 6 {
+		return fmt.Errorf("can not parse link comment \"%s\"", commentLine)
+	}
+
+	link := map[string]interface{}{"operationId": matches[3]}
+	if matches[4] != "" {
+		parameters := map[string]interface{}{}
+		for _, param := range strings.Split(matches[4], ",") {
+			parts := strings.SplitN(param, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return fmt.Errorf("link parameter %s should format: name=expression. comment=%s", param, commentLine)
+			}
+			parameters[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+		link["parameters"] = parameters
+	}
+	if matches[5] != "" {
+		link["description"] = matches[5]
+	}
+
+	addLink := func(response *spec.Response) {
+		links, _ := response.Extensions["x-links"].(map[string]interface{})
+		if links == nil {
+			links = map[string]interface{}{}
+		}
+		links[matches[2]] = link
+		response.AddExtension("x-links", links)
+	}
+
+	return operation.updateResponses(matches[1], "link", commentLine, addLink)
+}
+
+// updateResponses calls update for the declared responses of a comma separated list of status codes,
+// which may contain default.
+func (operation *Operation) updateResponses(codes, kind, commentLine string, update func(*spec.Response)) error {
+	for _, codeStr := range strings.Split(codes, ",") {
+		if strings.EqualFold(codeStr, "default") {
+			if operation.Responses == nil || operation.Responses.Default == nil {
+				return fmt.Errorf("%s of undeclared default response. comment=%s", kind, commentLine)
+			}
+			update(operation.Responses.Default)
+			continue
+		}
+
+		code, err := strconv.Atoi(codeStr)
+		if err != nil {
+			return fmt.Errorf("can not parse %s comment \"%s\"", kind, commentLine)
+		}
+		if operation.Responses == nil {
+			return fmt.Errorf("%s of undeclared response %d. comment=%s", kind, code, commentLine)
+		}
+		response, ok := operation.Responses.StatusCodeResponses[code]
+		if !ok {
+			return fmt.Errorf("%s of undeclared response %d. comment=%s", kind, code, commentLine)
+		}
+		update(&response)
+		operation.Responses.StatusCodeResponses[code] = response
+	}
+
+	return nil
+}
+
+var exampleRefPattern = regexp.MustCompile(`^([\w,]+)[\s]+([\w\-\.]+)$`)
+
+// ParseResponseExampleComment parses a reference from responses to an example declared by the general
+// @example annotation, eg: @ExampleRef 200,201 Pet
+func (operation *Operation) ParseResponseExampleComment(commentLine string) error {
+	matches := exampleRefPattern.FindStringSubmatch(commentLine)
+	if len(matches) != 3 {
+		return fmt.Errorf("can not parse example comment \"%s\"", commentLine)
+	}
+	if err := operation.checkNamedExample(matches[2], commentLine); err != nil {
+		return err
+	}
+
+	return operation.updateResponses(matches[1], "example", commentLine, func(response *spec.Response) {
+		refs, _ := response.Extensions["x-example-refs"].([]string)
+		response.AddExtension("x-example-refs", append(refs, matches[2]))
+	})
+}
+
+// checkNamedExample returns an error unless an example of name is declared by the general @example annotation.
+func (operation *Operation) checkNamedExample(name, commentLine string) error {
+	examples, _ := operation.parser.swagger.Extensions["x-examples"].(map[string]interface{})
+	if _, ok := examples[name]; !ok {
+		return fmt.Errorf("example %s isn't declared by @example. comment=%s", name, commentLine)
+	}
+	return nil
+}
+
 var emptyResponsePattern = regexp.MustCompile(`([\w,]+)[\s]+"(.*)"`)
 
 // ParseEmptyResponseComment parse only comment out status code and description,eg: @Success 200 "it's ok"
@@ -843,6 +1223,8 @@ func (operation *Operation) ParseEmptyResponseComment(commentLine string) error
 			var response spec.Response
 			response.Description = responseDescription
 			operation.AddResponse(code, &response)
+		} else if _, ok := statusCodeRanges[strings.ToUpper(codeStr)]; ok {
+			operation.addRangeResponse(strings.ToUpper(codeStr), nil, responseDescription)
 		} else {
 			return fmt.Errorf("can not parse response comment \"%s\"", commentLine)
 		}
@@ -860,6 +1242,8 @@ func (operation *Operation) ParseEmptyResponseOnly(commentLine string) error {
 			var response spec.Response
 			//response.Description = http.StatusText(code)
 			operation.AddResponse(code, &response)
+		} else if _, ok := statusCodeRanges[strings.ToUpper(codeStr)]; ok {
+			operation.addRangeResponse(strings.ToUpper(codeStr), nil, "")
 		} else {
 			return fmt.Errorf("can not parse response comment \"%s\"", commentLine)
 		}
@@ -868,6 +1252,59 @@ func (operation *Operation) ParseEmptyResponseOnly(commentLine string) error {
 	return nil
 }
 
+// statusCodeRanges lists the common codes a status range like 4XX is expanded to,
+// as swagger 2.0 only knows single status codes. OpenAPI 3 docs get the range itself.
+var statusCodeRanges = map[string][]int{
+	"1XX": {http.StatusContinue, http.StatusSwitchingProtocols},
+	"2XX": {http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent},
+	"3XX": {http.StatusMovedPermanently, http.StatusFound, http.StatusNotModified},
+	"4XX": {http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+		http.StatusConflict, http.StatusUnprocessableEntity, http.StatusTooManyRequests},
+	"5XX": {http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+}
+
+// statusRangeTexts describes the status ranges documented without description in OpenAPI 3 docs
+var statusRangeTexts = map[string]string{
+	"1XX": "Informational",
+	"2XX": "Success",
+	"3XX": "Redirection",
+	"4XX": "Client Error",
+	"5XX": "Server Error",
+}
+
+// addRangeResponse adds the response for all codes of a status range, which aren't documented explicitly yet.
+// The range itself is kept in the x-status-ranges extension of the responses.
+func (operation *Operation) addRangeResponse(statusRange string, schema *spec.Schema, description string) {
+	if operation.Responses == nil {
+		operation.Responses = &spec.Responses{
+			ResponsesProps: spec.ResponsesProps{
+				StatusCodeResponses: make(map[int]spec.Response),
+			},
+		}
+	}
+	ranges, _ := operation.Responses.Extensions["x-status-ranges"].(map[string]spec.Response)
+	if ranges == nil {
+		ranges = make(map[string]spec.Response)
+	}
+	ranges[statusRange] = spec.Response{
+		ResponseProps: spec.ResponseProps{Schema: schema, Description: description},
+	}
+	operation.Responses.AddExtension("x-status-ranges", ranges)
+
+	for _, code := range statusCodeRanges[statusRange] {
+		if _, ok := operation.Responses.StatusCodeResponses[code]; ok {
+			continue
+		}
+		resp := &spec.Response{
+			ResponseProps: spec.ResponseProps{Schema: schema, Description: description},
+		}
+		if resp.Description == "" {
+			resp.Description = http.StatusText(code)
+		}
+		operation.AddResponse(code, resp)
+	}
+}
+
 //DefaultResponse return the default response member pointer
 func (operation *Operation) DefaultResponse() *spec.Response {
 	if operation.Responses.Default == nil {
@@ -184,6 +184,35 @@ func TestParseResponseCommentWithObjectType(t *testing.T) {
 	assert.Equal(t, expected, string(b))
 }
 
+func TestParseResponseCommentWithStatusRange(t *testing.T) {
+	operation := NewOperation(nil)
+	operation.parser.addTestType("model.ErrorResponse")
+
+	err := operation.ParseComment(`@Failure 404 {string} string "not found"`, nil)
+	assert.NoError(t, err)
+	err = operation.ParseComment(`@Failure 4XX {object} model.ErrorResponse "client error"`, nil)
+	assert.NoError(t, err)
+
+	codes := make([]int, 0, len(operation.Responses.StatusCodeResponses))
+	for code := range operation.Responses.StatusCodeResponses {
+		codes = append(codes, code)
+	}
+	assert.ElementsMatch(t, []int{400, 401, 403, 404, 409, 422, 429}, codes)
+
+	response := operation.Responses.StatusCodeResponses[400]
+	assert.Equal(t, "client error", response.Description)
+	assert.Equal(t, "#/definitions/model.ErrorResponse", response.Schema.Ref.String())
+
+	response = operation.Responses.StatusCodeResponses[404]
+	assert.Equal(t, "not found", response.Description)
+
+	operation = NewOperation(nil)
+	err = operation.ParseComment(`@Failure 5xx "server error"`, nil)
+	assert.NoError(t, err)
+	assert.Len(t, operation.Responses.StatusCodeResponses, 4)
+	assert.Equal(t, "server error", operation.Responses.StatusCodeResponses[503].Description)
+}
+
 func TestParseResponseCommentWithNestedPrimitiveType(t *testing.T) {
 	comment := `@Success 200 {object} model.CommonHeader{data=string,data2=int} "Error message, if code != 200`
 	operation := NewOperation(nil)
@@ -513,6 +542,56 @@ func TestParseResponseCommentWithDeepNestedFields(t *testing.T) {
 	assert.Equal(t, expected, string(b))
 }
 
+func TestParseResponseCommentWithNonStringMapKeys(t *testing.T) {
+	t.Parallel()
+
+	operation := NewOperation(nil)
+	operation.parser.addTestType("model.User")
+	operation.parser.addTestType("model.Key")
+
+	err := operation.ParseComment(`@Success 200 {object} map[int]model.User "by id"`, nil)
+	assert.NoError(t, err)
+	err = operation.ParseComment(`@Success 201 {object} map[[2]int]map[model.Key]model.User "by pair and key"`, nil)
+	assert.NoError(t, err)
+	err = operation.ParseComment(`@Failure 400 {object} map[string]any "free-form"`, nil)
+	assert.NoError(t, err)
+
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "responses": {
+        "200": {
+            "description": "by id",
+            "schema": {
+                "type": "object",
+                "additionalProperties": {
+                    "$ref": "#/definitions/model.User"
+                }
+            }
+        },
+        "201": {
+            "description": "by pair and key",
+            "schema": {
+                "type": "object",
+                "additionalProperties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.User"
+                    }
+                }
+            }
+        },
+        "400": {
+            "description": "free-form",
+            "schema": {
+                "type": "object",
+                "additionalProperties": true
+            }
+        }
+    }
+}`
+	assert.Equal(t, expected, string(b))
+}
+
 func TestParseResponseCommentWithNestedArrayMapFields(t *testing.T) {
 	comment := `@Success 200 {object} []map[string]model.CommonHeader{data1=[]map[string]model.Payload,data2=map[string][]int} "Error message, if code != 200`
 	operation := NewOperation(nil)
@@ -846,6 +925,44 @@ func TestParseResponseCommentWithHeaderForCodes(t *testing.T) {
 	assert.Error(t, err, "ParseComment should not fail")
 }
 
+func TestParseResponseLinkComment(t *testing.T) {
+	operation := NewOperation(nil)
+	err := operation.ParseComment(`@Success 201 "created"`, nil)
+	assert.NoError(t, err)
+	err = operation.ParseComment(`@Link 201 createdUser GetUser "id=$response.body#/id" "the created user"`, nil)
+	assert.NoError(t, err)
+	err = operation.ParseComment(`@Link 201 userPets ListPets`, nil)
+	assert.NoError(t, err)
+
+	b, _ := json.MarshalIndent(operation, "", "    ")
+
+	expected := `{
+    "responses": {
+        "201": {
+            "description": "created",
+            "x-links": {
+                "createdUser": {
+                    "description": "the created user",
+                    "operationId": "GetUser",
+                    "parameters": {
+                        "id": "$response.body#/id"
+                    }
+                },
+                "userPets": {
+                    "operationId": "ListPets"
+                }
+            }
+        }
+    }
+}`
+	assert.Equal(t, expected, string(b))
+
+	err = operation.ParseComment(`@Link 404 notFound GetUser`, nil)
+	assert.Error(t, err)
+	err = operation.ParseComment(`@Link 201 createdUser GetUser "$response.body#/id"`, nil)
+	assert.Error(t, err)
+}
+
 func TestParseEmptyResponseOnlyCode(t *testing.T) {
 	comment := `@Success 200`
 	operation := NewOperation(nil)
@@ -926,6 +1043,141 @@ func TestParseParamCommentByPathType(t *testing.T) {
 	assert.Equal(t, expected, string(b))
 }
 
+func TestParseParamCommentByCookie(t *testing.T) {
+	comment := `@Param session cookie string true "session id"`
+	operation := NewOperation(nil)
+	assert.EqualError(t, operation.ParseComment(comment, nil), "cookie is not supported paramType without OpenAPI 3")
+
+	operation = NewOperation(nil)
+	operation.parser.OpenAPI3 = true
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "type": "string",
+            "description": "session id",
+            "name": "session",
+            "in": "cookie",
+            "required": true
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+
+	comment = `@Param session cookie []string true "session id"`
+	operation = NewOperation(nil)
+	operation.parser.OpenAPI3 = true
+	assert.Error(t, operation.ParseComment(comment, nil))
+
+	comment = `@Param session jar string true "session id"`
+	operation = NewOperation(nil)
+	assert.EqualError(t, operation.ParseComment(comment, nil), "jar is not supported paramType")
+}
+
+func TestParseParamCommentWithIrregularWhitespace(t *testing.T) {
+	comment := "//\t@Param \t some_id   \tquery\t\tint   true \t \"Some ID\"\t default(1)  "
+	operation := NewOperation(nil)
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "type": "integer",
+            "default": 1,
+            "description": "Some ID",
+            "name": "some_id",
+            "in": "query",
+            "required": true
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParseParamCommentByBodyExample(t *testing.T) {
+	comment := `@Param body body model.User true "user" Example({"id":1,"name":"a (admin)"})`
+	operation := NewOperation(nil)
+	operation.parser.addTestType("model.User")
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "description": "user",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+                "allOf": [
+                    {
+                        "$ref": "#/definitions/model.User"
+                    }
+                ],
+                "example": {
+                    "id": 1,
+                    "name": "a (admin)"
+                }
+            }
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+
+	comment = `@Param body body model.User true "user" Example({"id":)`
+	operation = NewOperation(nil)
+	operation.parser.addTestType("model.User")
+	assert.Error(t, operation.ParseComment(comment, nil))
+}
+
+func TestParseParamCommentByArrayExample(t *testing.T) {
+	comment := `@Param ids query []int true "ids" Example([1, 2, 3])`
+	operation := NewOperation(nil)
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "type": "array",
+            "items": {
+                "type": "integer"
+            },
+            "example": [
+                1,
+                2,
+                3
+            ],
+            "description": "ids",
+            "name": "ids",
+            "in": "query",
+            "required": true
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+
+	comment = `@Param names query []string true "names" Example(a, b)`
+	operation = NewOperation(nil)
+	assert.NoError(t, operation.ParseComment(comment, nil))
+	assert.Equal(t, []interface{}{"a", "b"}, operation.Parameters[0].Example)
+
+	comment = `@Param ids query []int true "ids" Example(["a"])`
+	operation = NewOperation(nil)
+	assert.Error(t, operation.ParseComment(comment, nil))
+
+	comment = `@Param ids query []int true "ids" Example(1.5, 2)`
+	operation = NewOperation(nil)
+	assert.Error(t, operation.ParseComment(comment, nil))
+}
+
 // Test ParseParamComment Query Params
 func TestParseParamCommentBodyArray(t *testing.T) {
 	comment := `@Param names body []string true "Users List"`
@@ -1046,6 +1298,108 @@ func TestParseParamCommentByQueryType(t *testing.T) {
 	assert.Equal(t, expected, string(b))
 }
 
+func TestParseParamCommentByOptionalBoolQuery(t *testing.T) {
+	for _, comment := range []string{
+		`@Param verbose query bool false "Verbose output"`,
+		`@Param verbose query bool optional "Verbose output"`,
+	} {
+		operation := NewOperation(nil)
+		err := operation.ParseComment(comment, nil)
+
+		assert.NoError(t, err)
+		b, _ := json.MarshalIndent(operation, "", "    ")
+		expected := `{
+    "parameters": [
+        {
+            "type": "boolean",
+            "description": "Verbose output",
+            "name": "verbose",
+            "in": "query"
+        }
+    ]
+}`
+		assert.Equal(t, expected, string(b))
+	}
+}
+
+func TestParseParamCommentByQueryDeepObject(t *testing.T) {
+	comment := `@Param filter query model.Filter true "filter" style(deepObject) explode(true)`
+	operation := NewOperation(nil)
+
+	operation.parser.addTestType("model.Filter")
+	// swagger 2.0 params have no schema
+	assert.Error(t, operation.ParseComment(comment, nil))
+
+	operation = NewOperation(nil)
+	operation.parser.OpenAPI3 = true
+	operation.parser.addTestType("model.Filter")
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "x-explode": true,
+            "x-style": "deepObject",
+            "description": "filter",
+            "name": "filter",
+            "in": "query",
+            "required": true,
+            "schema": {
+                "$ref": "#/definitions/model.Filter"
+            }
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParseParamCommentByQueryExpand(t *testing.T) {
+	filter := PrimitiveSchema(OBJECT)
+	filter.Properties = spec.SchemaProperties{"name": *PrimitiveSchema(STRING)}
+
+	comment := `@Param filter query model.Filter true "filter" expand(true)`
+	operation := NewOperation(nil)
+	operation.parser.AddTypeOverride("model.Filter", filter)
+	assert.NoError(t, operation.ParseComment(comment, nil))
+	if assert.Len(t, operation.Parameters, 1) {
+		assert.Equal(t, "name", operation.Parameters[0].Name)
+		assert.Equal(t, "string", operation.Parameters[0].Type)
+	}
+
+	comment = `@Param filter query model.Filter true "filter" expand(false)`
+	operation = NewOperation(nil)
+	operation.parser.AddTypeOverride("model.Filter", filter)
+	assert.Error(t, operation.ParseComment(comment, nil))
+
+	operation = NewOperation(nil)
+	operation.parser.OpenAPI3 = true
+	operation.parser.AddTypeOverride("model.Filter", filter)
+	assert.NoError(t, operation.ParseComment(comment, nil))
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "x-style": "deepObject",
+            "description": "filter",
+            "name": "filter",
+            "in": "query",
+            "required": true,
+            "schema": {
+                "$ref": "#/definitions/model.Filter"
+            }
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+
+	comment = `@Param filter query model.Filter true "filter" expand(no)`
+	operation = NewOperation(nil)
+	operation.parser.AddTypeOverride("model.Filter", filter)
+	assert.Error(t, operation.ParseComment(comment, nil))
+}
+
 func TestParseParamCommentByBodyType(t *testing.T) {
 	comment := `@Param some_id body model.OrderRow true "Some ID"`
 	operation := NewOperation(nil)
@@ -1189,6 +1543,45 @@ func TestParseParamCommentByBodyTypeArrayOfPrimitiveGoWithDeepNestedFields(t *te
 	assert.Equal(t, expected, string(b))
 }
 
+func TestParseParamCommentByBodyTypeArrayOfInlineObject(t *testing.T) {
+	comment := `@Param items body []object{id=int,tags=[]string} true "items"`
+	operation := NewOperation(nil)
+
+	err := operation.ParseComment(comment, nil)
+	assert.NoError(t, err)
+
+	b, err := json.MarshalIndent(operation, "", "    ")
+	assert.NoError(t, err)
+	expected := `{
+    "parameters": [
+        {
+            "description": "items",
+            "name": "items",
+            "in": "body",
+            "required": true,
+            "schema": {
+                "type": "array",
+                "items": {
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "type": "integer"
+                        },
+                        "tags": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+}
+
 func TestParseParamCommentByBodyTypeErr(t *testing.T) {
 	comment := `@Param some_id body model.OrderRow true "Some ID"`
 	operation := NewOperation(nil)
@@ -1369,6 +1762,34 @@ func TestParseParamCommentByEnums(t *testing.T) {
 	assert.Error(t, operation.ParseComment(comment, nil))
 }
 
+func TestParseParamCommentByArrayEnums(t *testing.T) {
+	comment := `@Param roles query []string true "roles" Enums(admin,user)`
+	operation := NewOperation(nil)
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "type": "array",
+            "items": {
+                "enum": [
+                    "admin",
+                    "user"
+                ],
+                "type": "string"
+            },
+            "description": "roles",
+            "name": "roles",
+            "in": "query",
+            "required": true
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+}
+
 func TestParseParamCommentByMaxLength(t *testing.T) {
 	comment := `@Param some_id query string true "Some ID" MaxLength(10)`
 	operation := NewOperation(nil)
@@ -1488,6 +1909,33 @@ func TestParseParamCommentByMaximum(t *testing.T) {
 
 }
 
+func TestParseParamCommentByConflictingAttributes(t *testing.T) {
+	t.Parallel()
+
+	invalid := []string{
+		`@Param some_id query int true "Some ID" Enums(1, 2, 3) Minimum(2)`,
+		`@Param name query string true "Name" Enums(a, b) MaxLength(1)`,
+		`@Param ids query []int true "IDs" Enums(1, 2) Maximum(2)`,
+		`@Param some_id query int true "Some ID" Enums(1, 2) Default(3)`,
+		`@Param some_id query int true "Some ID" Minimum(10) Maximum(1)`,
+		`@Param name query string true "Name" MinLength(10) MaxLength(1)`,
+	}
+	for _, comment := range invalid {
+		err := NewOperation(nil).ParseComment(comment, nil)
+		assert.Error(t, err, comment)
+	}
+
+	valid := []string{
+		`@Param some_id query int true "Some ID" Enums(1, 2) Default(2)`,
+		`@Param some_id query int true "Some ID" Minimum(1) Maximum(1)`,
+		`@Param name query string true "Name" MinLength(1) MaxLength(10) Default(a)`,
+	}
+	for _, comment := range valid {
+		err := NewOperation(nil).ParseComment(comment, nil)
+		assert.NoError(t, err, comment)
+	}
+}
+
 func TestParseParamCommentByDefault(t *testing.T) {
 	comment := `@Param some_id query int true "Some ID" Default(10)`
 	operation := NewOperation(nil)
@@ -1510,6 +1958,27 @@ func TestParseParamCommentByDefault(t *testing.T) {
 	assert.Equal(t, expected, string(b))
 }
 
+func TestParseParamCommentByInternal(t *testing.T) {
+	comment := `@Param trace_id header string false "Trace ID" x-internal(true)`
+	operation := NewOperation(nil)
+	err := operation.ParseComment(comment, nil)
+
+	assert.NoError(t, err)
+	b, _ := json.MarshalIndent(operation, "", "    ")
+	expected := `{
+    "parameters": [
+        {
+            "type": "string",
+            "x-internal": true,
+            "description": "Trace ID",
+            "name": "trace_id",
+            "in": "header"
+        }
+    ]
+}`
+	assert.Equal(t, expected, string(b))
+}
+
 func TestParseIdComment(t *testing.T) {
 	comment := `@Id myOperationId`
 	operation := NewOperation(nil)
@@ -1591,6 +2060,15 @@ func TestParseSummary(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestParseSummaryVerbatim(t *testing.T) {
+	comment := "// @Summary \tGet user: by ID 🚀 (v2) — see https://example.com/docs, \"fast\"!  "
+	operation := NewOperation(nil)
+
+	err := operation.ParseComment(comment, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `Get user: by ID 🚀 (v2) — see https://example.com/docs, "fast"!`, operation.Summary)
+}
+
 func TestParseDeprecationDescription(t *testing.T) {
 	comment := `@Deprecated`
 	operation := NewOperation(nil)
@@ -2,9 +2,20 @@ package swag
 
 import (
 	"errors"
+	"fmt"
 	"go/ast"
+	"go/build"
+	"go/constant"
 	"go/token"
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
 )
 
 //PackagesDefinitions map[package import path]*PackageDefinitions
@@ -12,19 +23,52 @@ type PackagesDefinitions struct {
 	files             map[*ast.File]*AstFileInfo
 	packages          map[string]*PackageDefinitions
 	uniqueDefinitions map[string]*TypeSpecDef
+
+	// ambiguousDefinitions definitions sharing their full name, which are only found by their package path
+	ambiguousDefinitions map[string][]*TypeSpecDef
+
+	// buildTags tags satisfied by the build constraints of collected files besides GOOS, GOARCH and the release tags
+	buildTags []string
+
+	// goos, goarch the target platform files are collected for, the host by default
+	goos, goarch string
+
+	// reportedDotImports type names declared by several dot imported packages of a file, which were reported already
+	reportedDotImports map[string]bool
+
+	// typeOverrides definitions registered by Parser.AddTypeOverride, map key is their full name like time.Time
+	typeOverrides map[string]*TypeSpecDef
+
+	// parsedFiles files whose types were gathered by ParseTypes already
+	parsedFiles map[*ast.File]bool
 }
 
 //NewPackagesDefinitions create object PackagesDefinitions
 func NewPackagesDefinitions() *PackagesDefinitions {
 	return &PackagesDefinitions{
-		files:             make(map[*ast.File]*AstFileInfo),
-		packages:          make(map[string]*PackageDefinitions),
-		uniqueDefinitions: make(map[string]*TypeSpecDef),
+		files:                make(map[*ast.File]*AstFileInfo),
+		packages:             make(map[string]*PackageDefinitions),
+		uniqueDefinitions:    make(map[string]*TypeSpecDef),
+		ambiguousDefinitions: make(map[string][]*TypeSpecDef),
 	}
 }
 
-//CollectAstFile collect ast.file
+//SetBuildTags set the tags satisfied by the build constraints of collected files
+func (pkgs *PackagesDefinitions) SetBuildTags(tags []string) {
+	pkgs.buildTags = tags
+}
+
+//SetTarget set the GOOS and GOARCH of the platform files are collected for, empty ones keep the host's
+func (pkgs *PackagesDefinitions) SetTarget(goos, goarch string) {
+	pkgs.goos, pkgs.goarch = goos, goarch
+}
+
+//CollectAstFile collect ast.file, unless its file name or build constraints exclude it from the target platform
 func (pkgs *PackagesDefinitions) CollectAstFile(packageDir, path string, astFile *ast.File) {
+	if !pkgs.matchFile(path, astFile) {
+		return
+	}
+
 	if pkgs.files == nil {
 		pkgs.files = make(map[*ast.File]*AstFileInfo)
 	}
@@ -54,69 +98,322 @@ func (pkgs *PackagesDefinitions) CollectAstFile(packageDir, path string, astFile
 	}
 }
 
-//RangeFiles for range the collection of ast.File
+// matchFile whether the GOOS and GOARCH suffixes of the file name, like _windows.go, and the build constraints
+// in the header of astFile are satisfied by the target platform and the build tags, like the go command does.
+func (pkgs *PackagesDefinitions) matchFile(path string, astFile *ast.File) bool {
+	ctx := build.Default
+	if pkgs.goos != "" {
+		ctx.GOOS = pkgs.goos
+	}
+	if pkgs.goarch != "" {
+		ctx.GOARCH = pkgs.goarch
+	}
+	// like the go command, cgo is disabled when building for another platform
+	ctx.CgoEnabled = ctx.CgoEnabled && ctx.GOOS == build.Default.GOOS && ctx.GOARCH == build.Default.GOARCH
+	ctx.BuildTags = pkgs.buildTags
+	// the header is taken from astFile, which doesn't need to be on disk
+	ctx.OpenFile = func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(fileHeader(astFile))), nil
+	}
+
+	match, err := ctx.MatchFile(filepath.Dir(path), filepath.Base(path))
+	return err == nil && match
+}
+
+// fileHeader returns the comments before the package clause of astFile, which hold its build constraints,
+// and the package clause.
+func fileHeader(astFile *ast.File) string {
+	var header strings.Builder
+	for _, group := range astFile.Comments {
+		if group.Pos() >= astFile.Package {
+			break
+		}
+		for _, comment := range group.List {
+			header.WriteString(comment.Text)
+			header.WriteString("\n")
+		}
+		// constraints need a blank line before the package clause, the package doc is directly above it
+		if group != astFile.Doc {
+			header.WriteString("\n")
+		}
+	}
+	header.WriteString("package " + astFile.Name.Name + "\n")
+	return header.String()
+}
+
+//RangeFiles for range the collection of ast.File, ordered by their package path and path
+//so the generated docs don't depend on the order of iterating a map
 func (pkgs *PackagesDefinitions) RangeFiles(handle func(filename string, file *ast.File) error) error {
-	for file, info := range pkgs.files {
-		if err := handle(info.Path, file); err != nil {
+	for _, info := range pkgs.sortedFiles() {
+		if err := handle(info.Path, info.File); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-//ParseTypes parse types
+//ParseTypes parse types of the files collected since the last call
 //@Return parsed definitions
 func (pkgs *PackagesDefinitions) ParseTypes() (map[*TypeSpecDef]*Schema, error) {
+	if pkgs.parsedFiles == nil {
+		pkgs.parsedFiles = make(map[*ast.File]bool)
+	}
+	var files []*AstFileInfo
+	for _, info := range pkgs.sortedFiles() {
+		if !pkgs.parsedFiles[info.File] {
+			files = append(files, info)
+			pkgs.parsedFiles[info.File] = true
+		}
+	}
+
+	// the type specs of a file don't depend on other files, so they are gathered concurrently
+	fileTypeDefs := make([][]*TypeSpecDef, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fileTypeDefs[i] = fileTypeSpecDefs(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// merge in the order of the files, so the same definition wins a collision regardless of scheduling
 	parsedSchemas := make(map[*TypeSpecDef]*Schema)
-	for astFile, info := range pkgs.files {
-		for i := range astFile.Decls {
-			astDeclaration := astFile.Decls[i]
+	for _, typeSpecDefs := range fileTypeDefs {
+		for _, typeSpecDef := range typeSpecDefs {
+			if idt, ok := typeSpecDef.TypeSpec.Type.(*ast.Ident); ok && IsGolangPrimitiveType(idt.Name) {
+				parsedSchemas[typeSpecDef] = &Schema{
+					PkgPath: typeSpecDef.PkgPath,
+					Name:    typeDocName(typeSpecDef),
+					Schema:  PrimitiveSchema(TransToValidSchemeType(idt.Name)),
+				}
+			}
 
-			generalDeclaration, ok := astDeclaration.(*ast.GenDecl)
+			if pkgs.uniqueDefinitions == nil {
+				return nil, errors.New("could not parse types, as unique definitions were nil")
+			}
 
-			if !ok || generalDeclaration.Tok != token.TYPE {
-				continue
+			fullName := typeSpecDef.FullName()
+			if anotherTypeDef, ok := pkgs.uniqueDefinitions[fullName]; ok {
+				if typeSpecDef.PkgPath == anotherTypeDef.PkgPath {
+					continue
+				}
+				delete(pkgs.uniqueDefinitions, fullName)
+				pkgs.addAmbiguousDefinition(fullName, anotherTypeDef)
+				pkgs.addAmbiguousDefinition(fullName, typeSpecDef)
+			} else if _, ok := pkgs.ambiguousDefinitions[fullName]; ok {
+				// a third one mustn't become unique
+				pkgs.addAmbiguousDefinition(fullName, typeSpecDef)
+			} else {
+				pkgs.uniqueDefinitions[fullName] = typeSpecDef
 			}
 
-			for _, astSpec := range generalDeclaration.Specs {
-				if typeSpec, ok := astSpec.(*ast.TypeSpec); ok {
-					typeSpecDef := &TypeSpecDef{
-						PkgPath:  info.PackagePath,
-						File:     astFile,
-						TypeSpec: typeSpec,
-					}
+			pkgs.packages[typeSpecDef.PkgPath].TypeDefinitions[typeSpecDef.Name()] = typeSpecDef
+		}
+	}
 
-					if idt, ok := typeSpec.Type.(*ast.Ident); ok && IsGolangPrimitiveType(idt.Name) {
-						parsedSchemas[typeSpecDef] = &Schema{
-							PkgPath: typeSpecDef.PkgPath,
-							Name:    astFile.Name.Name,
-							Schema:  PrimitiveSchema(TransToValidSchemeType(idt.Name)),
-						}
-					}
+	pkgs.collectEnums(files)
+	for typeSpecDef, schema := range parsedSchemas {
+		if len(typeSpecDef.Enums) > 0 {
+			schema.Schema = EnumSchema(schema.Schema, typeSpecDef.Enums)
+		}
+	}
 
-					if pkgs.uniqueDefinitions == nil {
-						return nil, errors.New("could not parse types, as unique definitions were nil")
-					}
+	return parsedSchemas, nil
+}
 
-					fullName := typeSpecDef.FullName()
-					anotherTypeDef, ok := pkgs.uniqueDefinitions[fullName]
-					if ok {
-						if typeSpecDef.PkgPath == anotherTypeDef.PkgPath {
-							continue
-						} else {
-							delete(pkgs.uniqueDefinitions, fullName)
-						}
-					} else {
-						pkgs.uniqueDefinitions[fullName] = typeSpecDef
-					}
+// sortedFiles returns the collected files ordered by their package path and path.
+func (pkgs *PackagesDefinitions) sortedFiles() []*AstFileInfo {
+	files := make([]*AstFileInfo, 0, len(pkgs.files))
+	for _, info := range pkgs.files {
+		files = append(files, info)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].PackagePath != files[j].PackagePath {
+			return files[i].PackagePath < files[j].PackagePath
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
 
-					pkgs.packages[typeSpecDef.PkgPath].TypeDefinitions[typeSpecDef.Name()] = typeSpecDef
+// fileTypeSpecDefs returns the definitions of the types declared at the top level of a file.
+func fileTypeSpecDefs(info *AstFileInfo) []*TypeSpecDef {
+	var typeSpecDefs []*TypeSpecDef
+	for _, astDeclaration := range info.File.Decls {
+		generalDeclaration, ok := astDeclaration.(*ast.GenDecl)
+		if !ok || generalDeclaration.Tok != token.TYPE {
+			continue
+		}
 
-				}
+		for _, astSpec := range generalDeclaration.Specs {
+			if typeSpec, ok := astSpec.(*ast.TypeSpec); ok {
+				typeSpecDefs = append(typeSpecDefs, &TypeSpecDef{
+					PkgPath:    info.PackagePath,
+					File:       info.File,
+					TypeSpec:   typeSpec,
+					TypeParams: typeParamNames(typeSpec),
+				})
 			}
 		}
 	}
-	return parsedSchemas, nil
+	return typeSpecDefs
+}
+
+func (pkgs *PackagesDefinitions) addAmbiguousDefinition(fullName string, typeSpecDef *TypeSpecDef) {
+	if pkgs.ambiguousDefinitions == nil {
+		pkgs.ambiguousDefinitions = make(map[string][]*TypeSpecDef)
+	}
+	for _, typeDef := range pkgs.ambiguousDefinitions[fullName] {
+		if typeDef.PkgPath == typeSpecDef.PkgPath {
+			return
+		}
+	}
+	pkgs.ambiguousDefinitions[fullName] = append(pkgs.ambiguousDefinitions[fullName], typeSpecDef)
+}
+
+// ambiguousPackagePaths returns the sorted package paths of the definitions sharing the full name of typeName,
+// used in file, if there are several ones.
+func (pkgs *PackagesDefinitions) ambiguousPackagePaths(typeName string, file *ast.File) []string {
+	fullName := typeName
+	if !strings.ContainsRune(typeName, '.') && file != nil {
+		fullName = fullTypeName(file.Name.Name, typeName)
+	}
+
+	var pkgPaths []string
+	for _, typeDef := range pkgs.ambiguousDefinitions[fullName] {
+		pkgPaths = append(pkgPaths, typeDef.PkgPath)
+	}
+	sort.Strings(pkgPaths)
+	return pkgPaths
+}
+
+// collectEnums adds the constants declared with a type of their package to the Enums of the type.
+func (pkgs *PackagesDefinitions) collectEnums(files []*AstFileInfo) {
+	// files are sorted by path, which keeps the order of declaration in each package
+	for _, info := range files {
+		pd, ok := pkgs.packages[info.PackagePath]
+		if !ok || pd.Files[info.Path] != info.File {
+			continue
+		}
+		for _, decl := range info.File.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.CONST {
+				collectConstBlockEnums(pd, genDecl)
+			}
+		}
+	}
+}
+
+// collectConstBlockEnums adds the constants of a const block to the Enums of their type. Like the compiler,
+// a spec without type and values repeats the ones of the previous spec, with iota as the index of the spec.
+func collectConstBlockEnums(pd *PackageDefinitions, genDecl *ast.GenDecl) {
+	var typeExpr ast.Expr
+	var values []ast.Expr
+	for iota, astSpec := range genDecl.Specs {
+		valueSpec, ok := astSpec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			typeExpr, values = valueSpec.Type, valueSpec.Values
+		}
+
+		for i, name := range valueSpec.Names {
+			if name.Name == "_" || i >= len(values) {
+				continue
+			}
+			constType := typeExpr
+			if call, ok := values[i].(*ast.CallExpr); ok && constType == nil && len(call.Args) == 1 {
+				// Active = Status(iota)
+				constType = call.Fun
+			}
+			typeIdent, ok := constType.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			typeSpecDef, ok := pd.TypeDefinitions[typeIdent.Name]
+			if !ok {
+				continue
+			}
+			if value, ok := constValue(values[i], iota); ok {
+				typeSpecDef.Enums = append(typeSpecDef.Enums, EnumValue{Name: name.Name, Value: value})
+			}
+		}
+	}
+}
+
+// constValue evaluates a constant expression of literals and iota.
+func constValue(expr ast.Expr, iota int) (interface{}, bool) {
+	value := evalConst(expr, iota)
+	switch value.Kind() {
+	case constant.Int:
+		if i, exact := constant.Int64Val(value); exact {
+			return i, true
+		}
+	case constant.Float:
+		f, _ := constant.Float64Val(value)
+		return f, true
+	case constant.String:
+		return constant.StringVal(value), true
+	case constant.Bool:
+		return constant.BoolVal(value), true
+	}
+	return nil, false
+}
+
+func evalConst(expr ast.Expr, iota int) constant.Value {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(expr.Value, expr.Kind, 0)
+	case *ast.Ident:
+		switch expr.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota))
+		case "true", "false":
+			return constant.MakeBool(expr.Name == "true")
+		}
+	case *ast.ParenExpr:
+		return evalConst(expr.X, iota)
+	case *ast.CallExpr:
+		// conversion like Status(1)
+		if len(expr.Args) == 1 {
+			return evalConst(expr.Args[0], iota)
+		}
+	case *ast.UnaryExpr:
+		x := evalConst(expr.X, iota)
+		if isNumericConst(x) && (expr.Op == token.SUB || expr.Op == token.ADD) {
+			return constant.UnaryOp(expr.Op, x, 0)
+		}
+	case *ast.BinaryExpr:
+		x, y := evalConst(expr.X, iota), evalConst(expr.Y, iota)
+		switch {
+		case x.Kind() == constant.String && y.Kind() == constant.String && expr.Op == token.ADD:
+			return constant.BinaryOp(x, expr.Op, y)
+		case x.Kind() == constant.Int && y.Kind() == constant.Int && (expr.Op == token.SHL || expr.Op == token.SHR):
+			if shift, ok := constant.Uint64Val(y); ok {
+				return constant.Shift(x, expr.Op, uint(shift))
+			}
+		case x.Kind() == constant.Int && y.Kind() == constant.Int && expr.Op == token.QUO:
+			if constant.Sign(y) != 0 {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+		case isNumericConst(x) && isNumericConst(y) && (expr.Op == token.ADD || expr.Op == token.SUB || expr.Op == token.MUL):
+			return constant.BinaryOp(x, expr.Op, y)
+		}
+	}
+	return constant.MakeUnknown()
+}
+
+func isNumericConst(value constant.Value) bool {
+	return value.Kind() == constant.Int || value.Kind() == constant.Float
 }
 
 func (pkgs *PackagesDefinitions) findTypeSpec(pkgPath string, typeName string) *TypeSpecDef {
@@ -133,6 +430,24 @@ func (pkgs *PackagesDefinitions) findTypeSpec(pkgPath string, typeName string) *
 	return nil
 }
 
+// findTypeSpecByPackageName finds out the type definition in all collected packages with the given name,
+// the type must be defined by one of them only to be unambiguous
+func (pkgs *PackagesDefinitions) findTypeSpecByPackageName(pkgName string, typeName string) *TypeSpecDef {
+	var found *TypeSpecDef
+	for _, pd := range pkgs.packages {
+		if pd.Name != pkgName {
+			continue
+		}
+		if typeDef, ok := pd.TypeDefinitions[typeName]; ok {
+			if found != nil {
+				return nil
+			}
+			found = typeDef
+		}
+	}
+	return found
+}
+
 // findPackagePathFromImports finds out the package path of a package via ranging imports of a ast.File
 // @pkg the name of the target package
 // @file current ast.File in which to search imports
@@ -193,6 +508,36 @@ func (pkgs *PackagesDefinitions) findPackagePathFromImports(pkgName string, file
 	return ""
 }
 
+// findPackagePathFromDotImports finds out the package path of the dot imported package of file which declares
+// typeName. If several ones declare it, the first import wins and the ambiguity is reported.
+func (pkgs *PackagesDefinitions) findPackagePathFromDotImports(typeName string, file *ast.File) string {
+	var pkgPaths []string
+	for _, imp := range file.Imports {
+		if imp.Name != nil && imp.Name.Name == "." {
+			pkgPath := strings.Trim(imp.Path.Value, `"`)
+			if pkgs.findTypeSpec(pkgPath, typeName) != nil {
+				pkgPaths = append(pkgPaths, pkgPath)
+			}
+		}
+	}
+	if len(pkgPaths) == 0 {
+		return ""
+	}
+
+	if len(pkgPaths) > 1 {
+		key := strings.Join(pkgPaths, ",") + "." + typeName
+		if !pkgs.reportedDotImports[key] {
+			if pkgs.reportedDotImports == nil {
+				pkgs.reportedDotImports = make(map[string]bool)
+			}
+			pkgs.reportedDotImports[key] = true
+			Printf("warning: %s is declared by the dot imported packages %s, using the one of %s",
+				typeName, strings.Join(pkgPaths, ", "), pkgPaths[0])
+		}
+	}
+	return pkgPaths[0]
+}
+
 // FindTypeSpec finds out TypeSpecDef of a type by typeName
 // @typeName the name of the target type, if it starts with a package name, find its own package path from imports on top of @file
 // @file the ast.file in which @typeName is used
@@ -200,6 +545,8 @@ func (pkgs *PackagesDefinitions) findPackagePathFromImports(pkgName string, file
 func (pkgs *PackagesDefinitions) FindTypeSpec(typeName string, file *ast.File) *TypeSpecDef {
 	if IsGolangPrimitiveType(typeName) {
 		return nil
+	} else if typeDef := pkgs.findTypeOverride(typeName, file); typeDef != nil {
+		return typeDef
 	} else if file == nil { // for test
 		return pkgs.uniqueDefinitions[typeName]
 	}
@@ -219,18 +566,19 @@ func (pkgs *PackagesDefinitions) FindTypeSpec(typeName string, file *ast.File) *
 		}
 
 		if pkgPath == "" {
-			pkgDefinition := pkgs.packages["pkg/"+parts[0]]
-			if pkgDefinition == nil {
-				return pkgs.findTypeSpec(pkgPath, parts[1])
+			if pkgDefinition := pkgs.packages["pkg/"+parts[0]]; pkgDefinition != nil {
+				if typeDef := pkgDefinition.TypeDefinitions[parts[1]]; typeDef != nil {
+					return typeDef
+				}
 			}
+		}
 
-			typeDef := pkgDefinition.TypeDefinitions[parts[1]]
-			if typeDef != nil {
-				return typeDef
-			}
+		if typeDef := pkgs.findTypeSpec(pkgPath, parts[1]); typeDef != nil {
+			return typeDef
 		}
 
-		return pkgs.findTypeSpec(pkgPath, parts[1])
+		// the package may not be imported by file, but only transitively
+		return pkgs.findTypeSpecByPackageName(parts[0], parts[1])
 	}
 
 	if typeDef, ok := pkgs.uniqueDefinitions[fullTypeName(file.Name.Name, typeName)]; ok {
@@ -241,20 +589,167 @@ func (pkgs *PackagesDefinitions) FindTypeSpec(typeName string, file *ast.File) *
 		return typeDef
 	}
 
+	if pkgPath := pkgs.findPackagePathFromDotImports(typeName, file); pkgPath != "" {
+		return pkgs.findTypeSpec(pkgPath, typeName)
+	}
+
+	return nil
+}
+
+// findTypeOverride finds out the definition registered by Parser.AddTypeOverride for typeName. Its package is
+// resolved by the imports of @file, so it may be imported under another name, and a package of another path
+// doesn't match an override registered for a path, like the one of url.URL for net/url.
+func (pkgs *PackagesDefinitions) findTypeOverride(typeName string, file *ast.File) *TypeSpecDef {
+	if len(pkgs.typeOverrides) == 0 {
+		return nil
+	}
+	if file == nil || !strings.ContainsRune(typeName, '.') {
+		return pkgs.typeOverrides[typeName]
+	}
+
+	parts := strings.SplitN(typeName, ".", 2)
+	pkgPath, imported := pkgs.importedPackagePath(file, parts[0])
+	if !imported {
+		typeDef, ok := pkgs.typeOverrides[typeName]
+		if !ok || typeDef.PkgPath != "" && parts[0] == file.Name.Name {
+			// a type of the package of file itself
+			return nil
+		}
+		return typeDef
+	}
+
+	typeDef, ok := pkgs.typeOverrides[fullTypeName(assumedPackageName(pkgPath), parts[1])]
+	if !ok || typeDef.PkgPath != "" && typeDef.PkgPath != pkgPath {
+		return nil
+	}
+	return typeDef
+}
+
+// importedPackagePath returns the path of the package imported by file under pkgName.
+func (pkgs *PackagesDefinitions) importedPackagePath(file *ast.File, pkgName string) (string, bool) {
 	for _, imp := range file.Imports {
-		if imp.Name != nil && imp.Name.Name == "." {
-			pkgPath := strings.Trim(imp.Path.Value, `"`)
-			if typeDef := pkgs.findTypeSpec(pkgPath, typeName); typeDef != nil {
-				return typeDef
+		pkgPath := strings.Trim(imp.Path.Value, `"`)
+		if imp.Name != nil {
+			if imp.Name.Name == pkgName {
+				return pkgPath, true
 			}
+			continue
+		}
+		if pkgs.packageName(pkgPath) == pkgName {
+			return pkgPath, true
 		}
 	}
+	return "", false
+}
 
-	return nil
+// packageName returns the name of the package of pkgPath, the one of the collected package if there is one.
+func (pkgs *PackagesDefinitions) packageName(pkgPath string) string {
+	if pd, ok := pkgs.packages[pkgPath]; ok && pd.Name != "" {
+		return pd.Name
+	}
+	return assumedPackageName(pkgPath)
+}
+
+// assumedPackageName returns the name a package of pkgPath is usually declared with, its last path element
+// without a major version suffix, a go- prefix and anything from the first character which isn't allowed in
+// identifiers, like chi for github.com/go-chi/chi/v5 or yaml for gopkg.in/yaml.v2.
+func assumedPackageName(pkgPath string) string {
+	name := path.Base(pkgPath)
+	if isMajorVersion(name) && path.Dir(pkgPath) != "." {
+		name = path.Base(path.Dir(pkgPath))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// isMajorVersion reports whether the path element is a major version suffix like v2.
+func isMajorVersion(element string) bool {
+	if len(element) < 2 || element[0] != 'v' {
+		return false
+	}
+	for _, r := range element[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FindConstValue finds out the value of a const by its name, evaluated like the values of enums
+// @constName the name of the const, if it starts with a package name, find its own package path from imports on top of @file
+// @file the ast.file in which @constName is used
+// @return the value of the const, strings are unquoted
+func (pkgs *PackagesDefinitions) FindConstValue(constName string, file *ast.File) (string, bool) {
+	var files []*ast.File
+	if strings.ContainsRune(constName, '.') {
+		parts := strings.Split(constName, ".")
+		constName = parts[1]
+		if pd, ok := pkgs.packages[pkgs.findPackagePathFromImports(parts[0], file)]; ok {
+			for _, f := range pd.Files {
+				files = append(files, f)
+			}
+		}
+	} else if file != nil {
+		files = append(files, file)
+		if info, ok := pkgs.files[file]; ok {
+			if pd, ok := pkgs.packages[info.PackagePath]; ok {
+				for _, f := range pd.Files {
+					files = append(files, f)
+				}
+			}
+		}
+		for _, imp := range file.Imports {
+			if imp.Name == nil || imp.Name.Name != "." {
+				continue
+			}
+			if pd, ok := pkgs.packages[strings.Trim(imp.Path.Value, `"`)]; ok {
+				for _, f := range pd.Files {
+					files = append(files, f)
+				}
+			}
+		}
+	}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			// consts without values repeat the ones before them, like the iota of enums
+			var values []ast.Expr
+			for iota, astSpec := range genDecl.Specs {
+				valueSpec, ok := astSpec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				if len(valueSpec.Values) > 0 {
+					values = valueSpec.Values
+				}
+				for i, name := range valueSpec.Names {
+					if name.Name != constName || i >= len(values) {
+						continue
+					}
+					value, ok := constValue(values[i], iota)
+					if !ok {
+						return "", false
+					}
+					return fmt.Sprint(value), true
+				}
+			}
+		}
+	}
+
+	return "", false
 }
 
 func isAliasPkgName(file *ast.File, pkgName string) bool {
-	if file == nil && file.Imports == nil {
+	if file == nil || file.Imports == nil {
 		return false
 	}
 
@@ -5,15 +5,19 @@ import (
 	"fmt"
 	"go/ast"
 	"go/build"
+	"go/importer"
 	goparser "go/parser"
 	"go/token"
+	"go/types"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -34,6 +38,12 @@ const (
 
 	// SnakeCase indicates using SnakeCase strategy for struct field.
 	SnakeCase = "snakecase"
+
+	// EmbeddedFlatten indicates promoting the properties of embedded structs into the embedding struct.
+	EmbeddedFlatten = "flatten"
+
+	// EmbeddedAllOf indicates composing the embedding struct by allOf of the definitions of embedded structs.
+	EmbeddedAllOf = "allof"
 )
 
 var (
@@ -43,6 +53,9 @@ var (
 	//ErrFuncTypeField field type is func
 	ErrFuncTypeField = errors.New("field type is func")
 
+	//ErrChanTypeField field type is chan
+	ErrChanTypeField = errors.New("field type is chan")
+
 	// ErrFailedConvertPrimitiveType Failed to convert for swag to interpretable type
 	ErrFailedConvertPrimitiveType = errors.New("swag property: failed convert primitive type")
 )
@@ -67,11 +80,17 @@ type Parser struct {
 	//toBeRenamedSchemas names of models to be renamed
 	toBeRenamedSchemas map[string]string
 
+	//namedDefinitions types named by @name, map key is the name
+	namedDefinitions map[string]*TypeSpecDef
+
 	//toBeRenamedSchemas URLs of ref models to be renamed
 	toBeRenamedRefURLs []*url.URL
 
 	PropNamingStrategy string
 
+	// EmbeddedMode how embedded structs are rendered, EmbeddedFlatten by default or EmbeddedAllOf
+	EmbeddedMode string
+
 	ParseVendor bool
 
 	// ParseDependencies whether swag should be parse outside dependency folder
@@ -80,8 +99,102 @@ type Parser struct {
 	// ParseInternal whether swag should parse internal packages
 	ParseInternal bool
 
-	// structStack stores full names of the structures that were already parsed or are being parsed now
-	structStack []*TypeSpecDef
+	// StrictFieldTypes whether swag should fail on func and chan fields instead of skipping them
+	StrictFieldTypes bool
+
+	// ParseUnexportedFields whether swag should include unexported fields with an explicit json or swaggertype tag
+	ParseUnexportedFields bool
+
+	// OpenAPI3 whether the docs are converted to OpenAPI 3.x, which allows 3.x only annotations like cookie params
+	// and marks the items of pointer slices as nullable
+	OpenAPI3 bool
+
+	// ParseFuncLocalTypes whether the operation of a function may refer to types declared in the function
+	ParseFuncLocalTypes bool
+
+	// BuildTags tags satisfied by the build constraints of parsed files besides GOOS, GOARCH and the release tags
+	BuildTags []string
+
+	// GOOS, GOARCH the target platform whose files, by their build constraints and file name suffixes like
+	// _windows.go, are parsed, the host by default
+	GOOS, GOARCH string
+
+	// ValidateTagName name of the struct tag with go-playground/validator rules, like validate or binding with Gin,
+	// which are turned into validation keywords
+	ValidateTagName string
+
+	// Int64AsString whether int64 and uint64 are rendered as strings with their format, so JavaScript clients
+	// don't lose precision
+	Int64AsString bool
+
+	// OmitEmptyExtension whether properties with the json omitempty option get the x-omitempty extension
+	OmitEmptyExtension bool
+
+	// EnumRefs whether the schemas of enum types, like the constants of type Status string, become definitions which
+	// fields refer to, instead of repeating their values in every field
+	EnumRefs bool
+
+	// FakerTag the struct tag, like faker:"email", used to generate example values of string fields without an example tag
+	FakerTag string
+
+	// CommentSources glob patterns, relative to the search dir, of non-Go files to parse annotations from
+	CommentSources []string
+
+	// ReadOnlyPatterns glob patterns, like ID or *At, of field or property names which are marked readOnly
+	ReadOnlyPatterns []string
+
+	// WriteOnlyPatterns glob patterns, like Password or *Secret, of field or property names which are marked writeOnly
+	// by the x-writeOnly extension, since Swagger 2.0 lacks the keyword
+	WriteOnlyPatterns []string
+
+	// AutoCreateTags whether tags used by operations are added to the root tags if they aren't declared by @tag.name
+	AutoCreateTags bool
+
+	// SummaryFromFuncName whether an operation without @Summary gets a summary derived from the name of its function,
+	// like Get user by ID for GetUserByID
+	SummaryFromFuncName bool
+
+	// DependencyPrefixes module path prefixes, like github.com/myorg, of the dependencies whose packages are collected
+	// once a type refers to them. Unlike ParseDependency only the referenced packages are parsed, from the module cache.
+	DependencyPrefixes []string
+
+	// RouterPrefixTrim a leading path prefix, like /api of a gateway mounting the API, which is trimmed from every
+	// @Router path. Only whole path segments are trimmed, /api doesn't trim /apis.
+	RouterPrefixTrim string
+
+	// CollectDiagnostics whether ParseAPI keeps parsing after malformed operation annotations and returns all
+	// of them with their positions as Diagnostics
+	CollectDiagnostics bool
+
+	// InlineResponseName names the definitions which inline response objects, like object{id=int} or
+	// Response{data=User}, are turned into, given the id of their operation and their status code or default.
+	// A response it returns no name for stays inline, like all of them without it. See OperationResponseName.
+	InlineResponseName func(operationID, status string) string
+
+	// JSONNumberAsString whether json.Number fields are rendered as strings instead of numbers
+	JSONNumberAsString bool
+
+	// TreatAsString full names of types, like model.Status, which are rendered as plain strings
+	TreatAsString []string
+
+	// NullableOmitEmpty whether pointer fields with the json omitempty option are never required,
+	// even when a binding or validate tag requires them
+	NullableOmitEmpty bool
+
+	// replacedModules local directories of the modules replaced in go.mod, map key is the module path
+	replacedModules map[string]string
+
+	// parsingTypes path qualified full names of the types being parsed now, a reference to one of them is recursive
+	parsingTypes map[string]bool
+
+	// genericDefinitions instantiations of generic types, map key is their full name like model.Page[model.User]
+	genericDefinitions map[string]*TypeSpecDef
+
+	// typeArgs type arguments bound to the type parameters of the generic type being parsed now
+	typeArgs map[string]*typeArg
+
+	// localTypes types declared in the function whose operation is being parsed now, map key is the type name
+	localTypes map[string]*TypeSpecDef
 
 	// markdownFileDir holds the path to the folder, where markdown files are stored
 	markdownFileDir string
@@ -94,6 +207,27 @@ type Parser struct {
 
 	// excludes excludes dirs and files in SearchDir
 	excludes map[string]bool
+
+	// producesByPackage mime types of the @Produce declared in the package doc comments, map key is the package path
+	producesByPackage map[string][]string
+
+	// collectedDependencies import paths of the dependency packages collected on demand, or which failed to be
+	collectedDependencies map[string]bool
+
+	// findPackageDir returns the directory of the package importPath as imported by the Go files of srcDir
+	findPackageDir func(importPath, srcDir string) (string, error)
+
+	// fileSet positions of the parsed files
+	fileSet *token.FileSet
+
+	// typeOptionsRegistered whether the type overrides of the options were registered
+	typeOptionsRegistered bool
+
+	// stdLibImporter type checks the standard library packages of embedded types, it keeps the checked ones
+	stdLibImporter types.Importer
+
+	// diagnostics problems found while parsing with CollectDiagnostics
+	diagnostics Diagnostics
 }
 
 // New creates a new Parser with default properties.
@@ -121,9 +255,26 @@ func New(options ...func(*Parser)) *Parser {
 		outputSchemas:      make(map[*TypeSpecDef]*Schema),
 		existSchemaNames:   make(map[string]*Schema),
 		toBeRenamedSchemas: make(map[string]string),
+		namedDefinitions:   make(map[string]*TypeSpecDef),
+		genericDefinitions: make(map[string]*TypeSpecDef),
+		parsingTypes:       make(map[string]bool),
+		ValidateTagName:    "validate",
 		excludes:           make(map[string]bool),
+		fileSet:            token.NewFileSet(),
+
+		collectedDependencies: make(map[string]bool),
+		findPackageDir:        getPkgDir,
 	}
 
+	// standard library types which aren't parsed from source
+	parser.addTypeOverride("time", "Time", formattedSchema(STRING, "date-time"))
+	// any json value
+	parser.addTypeOverride("encoding/json", "RawMessage", &spec.Schema{})
+	parser.addTypeOverride("encoding/json", "Number", PrimitiveSchema(NUMBER))
+	// net.IP holds ipv4 and ipv6 addresses, a format tag like format:"ipv6" tells which
+	parser.addTypeOverride("net", "IP", PrimitiveSchema(STRING))
+	parser.addTypeOverride("net/url", "URL", formattedSchema(STRING, "uri"))
+
 	for _, option := range options {
 		option(parser)
 	}
@@ -145,7 +296,9 @@ func SetCodeExamplesDirectory(directoryPath string) func(*Parser) {
 	}
 }
 
-// SetExcludedDirsAndFiles sets directories and files to be excluded when searching
+// SetExcludedDirsAndFiles sets directories and files to be excluded when searching, comma separated.
+// Besides paths like the walked ones, path prefixes and glob patterns relative to the search dir exclude
+// what they match, case-insensitively on Windows.
 func SetExcludedDirsAndFiles(excludes string) func(*Parser) {
 	return func(p *Parser) {
 		for _, f := range strings.Split(excludes, ",") {
@@ -158,10 +311,22 @@ func SetExcludedDirsAndFiles(excludes string) func(*Parser) {
 	}
 }
 
+// OperationResponseName names an inline response object by the id of its operation and its status, like
+// getUser_200, and leaves the responses of operations without id inline. It's meant for InlineResponseName.
+func OperationResponseName(operationID, status string) string {
+	if operationID == "" {
+		return ""
+	}
+	return operationID + "_" + status
+}
+
 // ParseAPI parses general api info for given searchDir and mainAPIFile
 func (parser *Parser) ParseAPI(searchDir, mainAPIFile string, parseDepth int) error {
 	Printf("Generate general API Info, search dir:%s", searchDir)
 
+	parser.packages.SetBuildTags(parser.BuildTags)
+	parser.packages.SetTarget(parser.GOOS, parser.GOARCH)
+
 	packageDir, err := getPkgName(searchDir)
 	if err != nil {
 		Printf("warning: failed to get package name in dir: %s, error: %s", searchDir, err.Error())
@@ -175,8 +340,15 @@ func (parser *Parser) ParseAPI(searchDir, mainAPIFile string, parseDepth int) er
 	if err != nil {
 		return err
 	}
+	if absMainAPIFilePath, err = parser.selectMainAPIFile(absMainAPIFilePath); err != nil {
+		return err
+	}
 
 	if parser.ParseDependency {
+		if err := parser.getAllGoFileInfoFromReplaces(searchDir); err != nil {
+			return errors.Wrap(err, "could not parse dependencies")
+		}
+
 		var t depth.Tree
 		t.ResolveInternal = true
 		t.MaxDepth = parseDepth
@@ -197,24 +369,103 @@ func (parser *Parser) ParseAPI(searchDir, mainAPIFile string, parseDepth int) er
 		}
 	}
 
+	if err = parser.parseGeneralAPIInfoFromPackageDocs(searchDir, absMainAPIFilePath); err != nil {
+		return err
+	}
+
 	if err = parser.ParseGeneralAPIInfo(absMainAPIFilePath); err != nil {
 		return err
 	}
 
-	parser.parsedSchemas, err = parser.packages.ParseTypes()
+	parsedSchemas, err := parser.packages.ParseTypes()
 	if err != nil {
 		return err
 	}
+	for typeSpecDef, schema := range parsedSchemas {
+		parser.parsedSchemas[typeSpecDef] = schema
+	}
 
 	if err = parser.packages.RangeFiles(parser.ParseRouterAPIInfo); err != nil {
 		return err
 	}
 
+	if err = parser.parseCommentSources(searchDir); err != nil {
+		return err
+	}
+
+	if parser.AutoCreateTags {
+		parser.addMissingTags()
+	}
+
 	parser.renameRefSchemas()
 
+	if len(parser.diagnostics) > 0 {
+		return parser.diagnostics
+	}
+
 	return parser.checkOperationIDUniqueness()
 }
 
+// ParsePackage parses the types declared in the Go files of dir, without its sub directories, and returns their
+// schemas keyed by type name. Unlike ParseAPI it doesn't need a main file nor general API info, and repeated calls
+// accumulate the types, so a package may refer to the ones of a package parsed before.
+// The definitions of referenced types are found in the swagger of the parser. It's the only API to parse a single
+// package, PackagesDefinitions only collects the types, the options of the parser decide their schemas.
+func (parser *Parser) ParsePackage(dir string) (map[string]*Schema, error) {
+	pkgPath, err := getPkgName(dir)
+	if err != nil {
+		Printf("warning: failed to get package name in dir: %s, error: %s", dir, err.Error())
+		pkgPath = filepath.ToSlash(filepath.Clean(dir))
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := parser.parseFile(pkgPath, filepath.Join(dir, f.Name()), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	parsedSchemas, err := parser.packages.ParseTypes()
+	if err != nil {
+		return nil, err
+	}
+	for typeSpecDef, schema := range parsedSchemas {
+		parser.parsedSchemas[typeSpecDef] = schema
+	}
+
+	schemas := make(map[string]*Schema)
+	pd, ok := parser.packages.packages[pkgPath]
+	if !ok {
+		return schemas, nil
+	}
+	names := make([]string, 0, len(pd.TypeDefinitions))
+	for name := range pd.TypeDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		typeSpecDef := pd.TypeDefinitions[name]
+		if len(typeSpecDef.TypeParams) > 0 {
+			// a generic type has a schema per instantiation only
+			continue
+		}
+		schema, err := parser.ParseDefinition(typeSpecDef)
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = schema
+	}
+
+	return schemas, nil
+}
+
 func getPkgName(searchDir string) (string, error) {
 	cmd := exec.Command("go", "list", "-f={{.ImportPath}}")
 	cmd.Dir = searchDir
@@ -239,6 +490,124 @@ func getPkgName(searchDir string) (string, error) {
 	return outStr, nil
 }
 
+// getPkgDir returns the directory of the package importPath as resolved by the module of srcDir,
+// which is in the module cache for a dependency.
+func getPkgDir(importPath, srcDir string) (string, error) {
+	cmd := exec.Command("go", "list", "-f={{.Dir}}", importPath)
+	cmd.Dir = srcDir
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("execute go list command, %s, stdout:%s, stderr:%s", err, stdout.String(), stderr.String())
+	}
+
+	dir := strings.TrimSpace(stdout.String())
+	if dir == "" {
+		return "", fmt.Errorf("package %s has no directory", importPath)
+	}
+	return dir, nil
+}
+
+// findGoMod returns the path of the go.mod file of dir or of its closest parent directory, or "" if there's none.
+func findGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		goModPath := filepath.Join(dir, "go.mod")
+		if info, err := os.Stat(goModPath); err == nil && !info.IsDir() {
+			return goModPath, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// parseLocalReplaces returns the absolute directories of the modules replaced by local paths in the go.mod file,
+// map key is the module path.
+func parseLocalReplaces(goModPath string) (map[string]string, error) {
+	cmd := exec.Command("go", "mod", "edit", "-json", goModPath)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("execute go mod edit command, %s, stdout:%s, stderr:%s", err, stdout.String(), stderr.String())
+	}
+
+	var goMod struct {
+		Replace []struct {
+			Old struct{ Path string }
+			New struct{ Path, Version string }
+		}
+	}
+	if err := json.Unmarshal([]byte(stdout.String()), &goMod); err != nil {
+		return nil, err
+	}
+
+	replaces := make(map[string]string)
+	for _, replace := range goMod.Replace {
+		// a local path has no version
+		dir := replace.New.Path
+		if replace.New.Version != "" ||
+			!filepath.IsAbs(dir) && !strings.HasPrefix(dir, "./") && !strings.HasPrefix(dir, "../") {
+			continue
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(goModPath), filepath.FromSlash(dir))
+		}
+		replaces[replace.Old.Path] = filepath.Clean(dir)
+	}
+
+	return replaces, nil
+}
+
+// getAllGoFileInfoFromReplaces gets all Go source files information of the modules which the go.mod of searchDir
+// replaces by local directories, so their types are found by the module import paths.
+func (parser *Parser) getAllGoFileInfoFromReplaces(searchDir string) error {
+	goModPath, err := findGoMod(searchDir)
+	if err != nil || goModPath == "" {
+		return err
+	}
+
+	parser.replacedModules, err = parseLocalReplaces(goModPath)
+	if err != nil {
+		return err
+	}
+
+	modulePaths := make([]string, 0, len(parser.replacedModules))
+	for modulePath := range parser.replacedModules {
+		modulePaths = append(modulePaths, modulePath)
+	}
+	sort.Strings(modulePaths)
+
+	for _, modulePath := range modulePaths {
+		if err := parser.getAllGoFileInfo(modulePath, parser.replacedModules[modulePath]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isReplacedPackage whether the package belongs to a module replaced by a local directory.
+func (parser *Parser) isReplacedPackage(pkgPath string) bool {
+	for modulePath := range parser.replacedModules {
+		if pkgPath == modulePath || strings.HasPrefix(pkgPath, modulePath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func initIfEmpty(license *spec.License) *spec.License {
 	if license == nil {
 		return new(spec.License)
@@ -251,11 +620,7 @@ func initIfEmpty(license *spec.License) *spec.License {
 func (parser *Parser) ParseGeneralAPIInfo(mainAPIFile string) error {
 	fileSet := token.NewFileSet()
 
-	filePath := mainAPIFile
-
-	if !strings.Contains(mainAPIFile, "main.go") {
-		filePath = mainAPIFile + "/main.go"
-	}
+	filePath := mainAPIFilePath(mainAPIFile)
 
 	fileTree, err := goparser.ParseFile(fileSet, filePath, nil, goparser.ParseComments)
 	if err != nil {
@@ -263,7 +628,6 @@ func (parser *Parser) ParseGeneralAPIInfo(mainAPIFile string) error {
 	}
 
 	parser.swagger.Swagger = "2.0"
-	securityMap := map[string]*spec.SecurityScheme{}
 
 	for i := range fileTree.Comments {
 		comment := fileTree.Comments[i]
@@ -271,352 +635,1063 @@ func (parser *Parser) ParseGeneralAPIInfo(mainAPIFile string) error {
 			continue
 		}
 
-		comments := strings.Split(comment.Text(), "\n")
-		previousAttribute := ""
-		// parsing classic meta data model
-		for i, commentLine := range comments {
-			attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
-			value := strings.TrimSpace(commentLine[len(attribute):])
-			multilineBlock := false
-			if previousAttribute == attribute {
-				multilineBlock = true
-			}
-			switch attribute {
-			case "@version":
-				parser.swagger.Info.Version = value
-			case "@title":
-				parser.swagger.Info.Title = value
-			case "@description":
-				if multilineBlock {
-					parser.swagger.Info.Description += "\n" + value
-					continue
-				}
-				parser.swagger.Info.Description = value
-			case "@description.markdown":
-				commentInfo, err := getMarkdownForTag("api", parser.markdownFileDir)
-				if err != nil {
-					return err
-				}
-				parser.swagger.Info.Description = string(commentInfo)
-			case "@termsofservice":
-				parser.swagger.Info.TermsOfService = value
-			case "@contact.name":
-				parser.swagger.Info.Contact.Name = value
-			case "@contact.email":
-				parser.swagger.Info.Contact.Email = value
-			case "@contact.url":
-				parser.swagger.Info.Contact.URL = value
-			case "@license.name":
-				parser.swagger.Info.License = initIfEmpty(parser.swagger.Info.License)
-				parser.swagger.Info.License.Name = value
-			case "@license.url":
-				parser.swagger.Info.License = initIfEmpty(parser.swagger.Info.License)
-				parser.swagger.Info.License.URL = value
-			case "@host":
-				parser.swagger.Host = value
-			case "@basepath":
-				parser.swagger.BasePath = value
-			case "@schemes":
-				parser.swagger.Schemes = getSchemes(commentLine)
-			case "@tag.name":
-				parser.swagger.Tags = append(parser.swagger.Tags, spec.Tag{
-					TagProps: spec.TagProps{
-						Name: value,
-					},
-				})
-			case "@tag.description":
-				tag := parser.swagger.Tags[len(parser.swagger.Tags)-1]
-				tag.TagProps.Description = value
-				replaceLastTag(parser.swagger.Tags, tag)
-			case "@tag.description.markdown":
-				tag := parser.swagger.Tags[len(parser.swagger.Tags)-1]
-				commentInfo, err := getMarkdownForTag(tag.TagProps.Name, parser.markdownFileDir)
-				if err != nil {
-					return err
-				}
-				tag.TagProps.Description = string(commentInfo)
-				replaceLastTag(parser.swagger.Tags, tag)
-			case "@tag.docs.url":
-				tag := parser.swagger.Tags[len(parser.swagger.Tags)-1]
-				tag.TagProps.ExternalDocs = &spec.ExternalDocumentation{
-					URL: value,
-				}
-				replaceLastTag(parser.swagger.Tags, tag)
-			case "@tag.docs.description":
-				tag := parser.swagger.Tags[len(parser.swagger.Tags)-1]
-				if tag.TagProps.ExternalDocs == nil {
-					return fmt.Errorf("%s needs to come after a @tags.docs.url", attribute)
-				}
-				tag.TagProps.ExternalDocs.Description = value
-				replaceLastTag(parser.swagger.Tags, tag)
-			case "@securitydefinitions.basic":
-				securityMap[value] = spec.BasicAuth()
-			case "@securitydefinitions.apikey":
-				attrMap, _, _, err := extractSecurityAttribute(attribute, []string{"@in", "@name"}, comments[i+1:])
-				if err != nil {
-					return err
-				}
-				securityMap[value] = spec.APIKeyAuth(attrMap["@name"], attrMap["@in"])
-			case "@securitydefinitions.oauth2.application":
-				attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@tokenurl"}, comments[i+1:])
-				if err != nil {
-					return err
-				}
-				securityMap[value] = securitySchemeOAuth2Application(attrMap["@tokenurl"], scopes, extensions)
-			case "@securitydefinitions.oauth2.implicit":
-				attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@authorizationurl"}, comments[i+1:])
-				if err != nil {
-					return err
-				}
-				securityMap[value] = securitySchemeOAuth2Implicit(attrMap["@authorizationurl"], scopes, extensions)
-			case "@securitydefinitions.oauth2.password":
-				attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@tokenurl"}, comments[i+1:])
-				if err != nil {
-					return err
-				}
-				securityMap[value] = securitySchemeOAuth2Password(attrMap["@tokenurl"], scopes, extensions)
-			case "@securitydefinitions.oauth2.accesscode":
-				attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@tokenurl", "@authorizationurl"}, comments[i+1:])
-				if err != nil {
-					return err
-				}
-				securityMap[value] = securitySchemeOAuth2AccessToken(attrMap["@authorizationurl"], attrMap["@tokenurl"], scopes, extensions)
-			case "@x-tokenname":
-				// ignore this
-				break
-			case "@query.collection.format":
-				parser.collectionFormatInQuery = value
-			case "@x-taggroups":
-				originalAttribute := strings.Split(commentLine, " ")[0]
-				if len(value) == 0 {
-					return fmt.Errorf("annotation %s need a value", attribute)
-				}
+		if err := parser.parseGeneralAPIComment(comment); err != nil {
+			return err
+		}
+	}
 
-				var valueJSON interface{}
-				if err := json.Unmarshal([]byte(value), &valueJSON); err != nil {
-					return fmt.Errorf("annotation %s need a valid json value", originalAttribute)
-				}
+	return nil
+}
 
-				parser.swagger.Extensions[originalAttribute[1:]] = valueJSON // don't use the method provided by spec lib, cause it will call toLower() on attribute names, which is wrongy
-			default:
-				prefixExtension := "@x-"
-				if len(attribute) > 5 { // Prefix extension + 1 char + 1 space  + 1 char
-					if attribute[:len(prefixExtension)] == prefixExtension {
-						var valueJSON interface{}
-						split := strings.SplitAfter(commentLine, attribute+" ")
-						if len(split) < 2 {
-							return fmt.Errorf("annotation %s need a value", attribute)
-						}
-						extensionName := "x-" + strings.SplitAfter(attribute, prefixExtension)[1]
-						if err := json.Unmarshal([]byte(split[1]), &valueJSON); err != nil {
-							return fmt.Errorf("annotation %s need a valid json value", attribute)
-						}
-
-						if strings.Contains(extensionName, "logo") {
-							parser.swagger.Info.Extensions.Add(extensionName, valueJSON)
-						} else {
-							parser.swagger.AddExtension(extensionName, valueJSON)
-						}
-					}
-				}
+// mainAPIFilePath returns the path of the main file, which is main.go if mainAPIFile is a directory.
+func mainAPIFilePath(mainAPIFile string) string {
+	if filepath.Ext(mainAPIFile) != ".go" {
+		return filepath.Join(mainAPIFile, "main.go")
+	}
+	return mainAPIFile
+}
+
+// selectMainAPIFile returns the path of mainAPIFile, unless it doesn't exist or the build constraints exclude it,
+// like a main file per build tag. Then it's the first Go file of its directory which satisfies the build
+// constraints and declares the @title of the general API info.
+func (parser *Parser) selectMainAPIFile(mainAPIFile string) (string, error) {
+	filePath := mainAPIFilePath(mainAPIFile)
+	fileTree, err := goparser.ParseFile(token.NewFileSet(), filePath, nil, goparser.ParseComments)
+	switch {
+	case err == nil && parser.packages.matchFile(filePath, fileTree):
+		return filePath, nil
+	case err != nil && !os.IsNotExist(err):
+		// reported by ParseGeneralAPIInfo
+		return filePath, nil
+	}
+
+	dir := filepath.Dir(filePath)
+	files, readErr := ioutil.ReadDir(dir)
+	if readErr != nil {
+		return filePath, nil
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.Name())
+		if f.IsDir() || path == filePath || filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		candidate, err := goparser.ParseFile(token.NewFileSet(), path, nil, goparser.ParseComments)
+		if err != nil || !parser.packages.matchFile(path, candidate) || !hasGeneralAPITitle(candidate) {
+			continue
+		}
+		Printf("Main API file %s isn't part of the build, using %s", filePath, path)
+		return path, nil
+	}
+
+	if err == nil {
+		return "", fmt.Errorf("main API file %s is excluded by build constraints", filePath)
+	}
+	return filePath, nil
+}
+
+// hasGeneralAPITitle whether a comment of astFile declares the @title of the general API info.
+func hasGeneralAPITitle(astFile *ast.File) bool {
+	for _, comment := range astFile.Comments {
+		for _, commentLine := range commentLines(comment.Text()) {
+			if strings.ToLower(strings.Split(commentLine, " ")[0]) == "@title" {
+				return true
 			}
-			previousAttribute = attribute
 		}
 	}
+	return false
+}
 
-	if len(securityMap) > 0 {
-		parser.swagger.SecurityDefinitions = securityMap
+// parseGeneralAPIInfoFromPackageDocs parses general api info from the package doc comments of the files
+// under searchDir except mainAPIFile, so the annotations may live in a doc.go instead of the main file.
+// Vendored and dependency packages are skipped. Package docs must not contradict each other, they're parsed
+// before the main file, which wins over them.
+func (parser *Parser) parseGeneralAPIInfoFromPackageDocs(searchDir, mainAPIFile string) error {
+	absSearchDir, err := filepath.Abs(searchDir)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return parser.packages.RangeFiles(func(fileName string, astFile *ast.File) error {
+		if astFile.Doc == nil || !isGeneralAPIComment(astFile.Doc) || !hasAnnotation(astFile.Doc) {
+			return nil
+		}
+		absFileName, err := filepath.Abs(fileName)
+		if err != nil || absFileName == mainAPIFile || !isSearchDirFile(absSearchDir, absFileName) {
+			return nil
+		}
+
+		before := parser.generalAPIValues()
+		if err := parser.parseGeneralAPIComment(astFile.Doc); err != nil {
+			return fmt.Errorf("cannot parse general api info in file %s: %s", fileName, err)
+		}
+		for name, value := range parser.generalAPIValues() {
+			if previous, ok := before[name]; ok && !reflect.DeepEqual(previous, value) {
+				return fmt.Errorf("cannot parse general api info in file %s: conflicting values of %s: %v and %v",
+					fileName, name, previous, value)
+			}
+		}
+		return nil
+	})
 }
 
-func isGeneralAPIComment(comment *ast.CommentGroup) bool {
-	for _, commentLine := range strings.Split(comment.Text(), "\n") {
-		attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
-		switch attribute {
-		// The @summary, @router, @success,@failure  annotation belongs to Operation
-		case "@summary", "@router", "@success", "@failure", "@response":
+// isSearchDirFile reports whether fileName is under searchDir, outside of its vendor folders.
+func isSearchDirFile(searchDir, fileName string) bool {
+	rel, err := filepath.Rel(searchDir, fileName)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	for _, dir := range strings.Split(filepath.Dir(rel), string(filepath.Separator)) {
+		if dir == "vendor" {
 			return false
 		}
 	}
 	return true
 }
 
-func extractSecurityAttribute(context string, search []string, lines []string) (map[string]string, map[string]string, map[string]interface{}, error) {
-	attrMap := map[string]string{}
-	scopes := map[string]string{}
-	extensions := map[string]interface{}{}
-	for _, v := range lines {
-		securityAttr := strings.ToLower(strings.Split(v, " ")[0])
-		for _, findterm := range search {
-			if securityAttr == findterm {
-				attrMap[securityAttr] = strings.TrimSpace(v[len(securityAttr):])
-				continue
+// generalAPIValues returns the fields of the general api info which are set, keyed by names like info.version,
+// tags.users or securityDefinitions.BasicAuth, so the ones of package docs can be told apart.
+func (parser *Parser) generalAPIValues() map[string]interface{} {
+	values := map[string]interface{}{}
+	add := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+
+	info := parser.swagger.Info
+	add("info.title", info.Title)
+	add("info.version", info.Version)
+	add("info.description", info.Description)
+	add("info.termsOfService", info.TermsOfService)
+	if info.Contact != nil {
+		add("info.contact.name", info.Contact.Name)
+		add("info.contact.email", info.Contact.Email)
+		add("info.contact.url", info.Contact.URL)
+	}
+	if info.License != nil {
+		add("info.license.name", info.License.Name)
+		add("info.license.url", info.License.URL)
+	}
+	for key, value := range info.Extensions {
+		values["info."+key] = value
+	}
+	add("host", parser.swagger.Host)
+	add("basePath", parser.swagger.BasePath)
+	if len(parser.swagger.Schemes) > 0 {
+		values["schemes"] = append([]string(nil), parser.swagger.Schemes...)
+	}
+	if externalDocs := parser.swagger.ExternalDocs; externalDocs != nil {
+		add("externalDocs.url", externalDocs.URL)
+		add("externalDocs.description", externalDocs.Description)
+	}
+	for _, tag := range parser.swagger.Tags {
+		values["tags."+tag.Name] = tag
+	}
+	for name, scheme := range parser.swagger.SecurityDefinitions {
+		values["securityDefinitions."+name] = scheme
+	}
+	for key, value := range parser.swagger.Extensions {
+		if examples, ok := value.(map[string]interface{}); ok && key == "x-examples" {
+			// the examples are added to the same map
+			for name, example := range examples {
+				values[key+"."+name] = example
 			}
+			continue
 		}
-		isExists, err := isExistsScope(securityAttr)
-		if err != nil {
-			return nil, nil, nil, err
+		values[key] = value
+	}
+	add("query.collection.format", parser.collectionFormatInQuery)
+	return values
+}
+
+// commentLines splits the text of comments into lines without the carriage returns of Windows line endings.
+func commentLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func hasAnnotation(comment *ast.CommentGroup) bool {
+	for _, commentLine := range commentLines(comment.Text()) {
+		if strings.HasPrefix(commentLine, "@") {
+			return true
 		}
-		if isExists {
-			scopScheme, err := getScopeScheme(securityAttr)
-			if err != nil {
-				return nil, nil, nil, err
+	}
+	return false
+}
+
+func (parser *Parser) parseGeneralAPIComment(comment *ast.CommentGroup) error {
+	securityMap := map[string]*spec.SecurityScheme{}
+
+	comments := commentLines(comment.Text())
+	previousAttribute := ""
+	lastExample := ""
+	// tagIndex the tag described by @tag attributes, the last one unless a tag is declared anew
+	tagIndex := len(parser.swagger.Tags) - 1
+	// parsing classic meta data model
+	for i, commentLine := range comments {
+		attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
+		value := strings.TrimSpace(commentLine[len(attribute):])
+		multilineBlock := false
+		if previousAttribute == attribute {
+			multilineBlock = true
+		}
+		switch attribute {
+		case "@version":
+			parser.swagger.Info.Version = value
+		case "@title":
+			parser.swagger.Info.Title = value
+		case "@summary":
+			// OpenAPI 3.1 info.summary, swagger 2.0 has no such field
+			parser.swagger.Info.AddExtension("x-summary", value)
+		case "@description":
+			if multilineBlock {
+				parser.swagger.Info.Description += "\n" + value
+				continue
+			}
+			parser.swagger.Info.Description = value
+		case "@description.markdown":
+			commentInfo, err := getMarkdownForTag("api", parser.markdownFileDir)
+			if err != nil {
+				return err
+			}
+			parser.swagger.Info.Description = string(commentInfo)
+		case "@termsofservice":
+			parser.swagger.Info.TermsOfService = value
+		case "@contact.name":
+			parser.swagger.Info.Contact.Name = value
+		case "@contact.email":
+			parser.swagger.Info.Contact.Email = value
+		case "@contact.url":
+			parser.swagger.Info.Contact.URL = value
+		case "@license.name":
+			parser.swagger.Info.License = initIfEmpty(parser.swagger.Info.License)
+			parser.swagger.Info.License.Name = value
+		case "@license.url":
+			parser.swagger.Info.License = initIfEmpty(parser.swagger.Info.License)
+			parser.swagger.Info.License.URL = value
+		case "@host":
+			parser.swagger.Host = value
+		case "@basepath":
+			parser.swagger.BasePath = value
+		case "@schemes":
+			parser.swagger.Schemes = getSchemes(commentLine)
+		case "@externaldocs.url":
+			if parser.swagger.ExternalDocs == nil {
+				parser.swagger.ExternalDocs = &spec.ExternalDocumentation{}
+			}
+			parser.swagger.ExternalDocs.URL = value
+		case "@externaldocs.description":
+			if parser.swagger.ExternalDocs == nil {
+				parser.swagger.ExternalDocs = &spec.ExternalDocumentation{}
+			}
+			parser.swagger.ExternalDocs.Description = value
+		case "@tag.name":
+			// a tag declared before, like by a package doc, is declared anew in its place
+			tag := spec.Tag{
+				TagProps: spec.TagProps{
+					Name: value,
+				},
+			}
+			tagIndex = len(parser.swagger.Tags)
+			for i := range parser.swagger.Tags {
+				if parser.swagger.Tags[i].Name == value {
+					tagIndex = i
+					break
+				}
+			}
+			if tagIndex == len(parser.swagger.Tags) {
+				parser.swagger.Tags = append(parser.swagger.Tags, tag)
+			} else {
+				parser.swagger.Tags[tagIndex] = tag
+			}
+		case "@tag.description":
+			tag := parser.swagger.Tags[tagIndex]
+			tag.TagProps.Description = value
+			parser.swagger.Tags[tagIndex] = tag
+		case "@tag.description.markdown":
+			tag := parser.swagger.Tags[tagIndex]
+			commentInfo, err := getMarkdownForTag(tag.TagProps.Name, parser.markdownFileDir)
+			if err != nil {
+				return err
+			}
+			tag.TagProps.Description = string(commentInfo)
+			parser.swagger.Tags[tagIndex] = tag
+		case "@tag.docs.url":
+			tag := parser.swagger.Tags[tagIndex]
+			tag.TagProps.ExternalDocs = &spec.ExternalDocumentation{
+				URL: value,
+			}
+			parser.swagger.Tags[tagIndex] = tag
+		case "@tag.docs.description":
+			tag := parser.swagger.Tags[tagIndex]
+			if tag.TagProps.ExternalDocs == nil {
+				return fmt.Errorf("%s needs to come after a @tags.docs.url", attribute)
+			}
+			tag.TagProps.ExternalDocs.Description = value
+			parser.swagger.Tags[tagIndex] = tag
+		case "@securitydefinitions.basic":
+			securityMap[value] = spec.BasicAuth()
+		case "@securitydefinitions.bearer":
+			securityMap[value] = securitySchemeBearer(extractBearerFormat(comments[i+1:]))
+		case "@securitydefinitions.apikey":
+			attrMap, _, _, err := extractSecurityAttribute(attribute, []string{"@in", "@name"}, comments[i+1:])
+			if err != nil {
+				return err
+			}
+			securityMap[value] = spec.APIKeyAuth(attrMap["@name"], attrMap["@in"])
+		case "@securitydefinitions.oauth2.application":
+			attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@tokenurl"}, comments[i+1:])
+			if err != nil {
+				return err
+			}
+			securityMap[value] = securitySchemeOAuth2Application(attrMap["@tokenurl"], scopes, extensions)
+		case "@securitydefinitions.oauth2.implicit":
+			attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@authorizationurl"}, comments[i+1:])
+			if err != nil {
+				return err
+			}
+			securityMap[value] = securitySchemeOAuth2Implicit(attrMap["@authorizationurl"], scopes, extensions)
+		case "@securitydefinitions.oauth2.password":
+			attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@tokenurl"}, comments[i+1:])
+			if err != nil {
+				return err
+			}
+			securityMap[value] = securitySchemeOAuth2Password(attrMap["@tokenurl"], scopes, extensions)
+		case "@securitydefinitions.oauth2.accesscode":
+			attrMap, scopes, extensions, err := extractSecurityAttribute(attribute, []string{"@tokenurl", "@authorizationurl"}, comments[i+1:])
+			if err != nil {
+				return err
+			}
+			securityMap[value] = securitySchemeOAuth2AccessToken(attrMap["@authorizationurl"], attrMap["@tokenurl"], scopes, extensions)
+		case "@example":
+			name, example, err := parseNamedExample(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", commentLine, err)
+			}
+			parser.namedExamples()[name] = example
+			lastExample = name
+		case "@example.summary":
+			example, ok := parser.namedExamples()[lastExample].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s needs a preceding @example", attribute)
+			}
+			example["summary"] = value
+		case "@x-tokenname":
+			// ignore this
+			break
+		case "@query.collection.format":
+			parser.collectionFormatInQuery = value
+		case "@x-taggroups":
+			originalAttribute := strings.Split(commentLine, " ")[0]
+			if len(value) == 0 {
+				return fmt.Errorf("annotation %s need a value", attribute)
+			}
+
+			var valueJSON interface{}
+			if err := json.Unmarshal([]byte(value), &valueJSON); err != nil {
+				return fmt.Errorf("annotation %s need a valid json value", originalAttribute)
+			}
+
+			parser.swagger.Extensions[originalAttribute[1:]] = valueJSON // don't use the method provided by spec lib, cause it will call toLower() on attribute names, which is wrongy
+		default:
+			prefixExtension := "@x-"
+			if len(attribute) > 5 { // Prefix extension + 1 char + 1 space  + 1 char
+				if attribute[:len(prefixExtension)] == prefixExtension {
+					var valueJSON interface{}
+					split := strings.SplitAfter(commentLine, attribute+" ")
+					if len(split) < 2 {
+						return fmt.Errorf("annotation %s need a value", attribute)
+					}
+					extensionName := "x-" + strings.SplitAfter(attribute, prefixExtension)[1]
+					if err := json.Unmarshal([]byte(split[1]), &valueJSON); err != nil {
+						return fmt.Errorf("annotation %s need a valid json value", attribute)
+					}
+
+					if strings.Contains(extensionName, "logo") {
+						parser.swagger.Info.Extensions.Add(extensionName, valueJSON)
+					} else {
+						parser.swagger.AddExtension(extensionName, valueJSON)
+					}
+				}
+			}
+		}
+		previousAttribute = attribute
+	}
+
+	if len(securityMap) > 0 {
+		if parser.swagger.SecurityDefinitions == nil {
+			parser.swagger.SecurityDefinitions = securityMap
+		} else {
+			for name, scheme := range securityMap {
+				parser.swagger.SecurityDefinitions[name] = scheme
+			}
+		}
+	}
+
+	return nil
+}
+
+func isGeneralAPIComment(comment *ast.CommentGroup) bool {
+	for _, commentLine := range commentLines(comment.Text()) {
+		attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
+		switch attribute {
+		// The @router, @success, @failure, @response, @param annotation belongs to Operation
+		case "@router", "@success", "@failure", "@response", "@param":
+			return false
+		}
+	}
+	return true
+}
+
+func extractSecurityAttribute(context string, search []string, lines []string) (map[string]string, map[string]string, map[string]interface{}, error) {
+	attrMap := map[string]string{}
+	scopes := map[string]string{}
+	extensions := map[string]interface{}{}
+	for _, v := range lines {
+		securityAttr := strings.ToLower(strings.Split(v, " ")[0])
+		for _, findterm := range search {
+			if securityAttr == findterm {
+				attrMap[securityAttr] = strings.TrimSpace(v[len(securityAttr):])
+				continue
+			}
+		}
+		isExists, err := isExistsScope(securityAttr)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if isExists {
+			scopScheme, err := getScopeScheme(securityAttr)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			scopes[scopScheme] = v[len(securityAttr):]
+		}
+		if securityAttr == "@x-tokenname" {
+			extensions["x-tokenName"] = strings.TrimSpace(v[len(securityAttr):])
+		}
+		// next securityDefinitions
+		if strings.Index(securityAttr, "@securitydefinitions.") == 0 {
+			break
+		}
+	}
+	if len(attrMap) != len(search) {
+		return nil, nil, nil, fmt.Errorf("%s is %v required", context, search)
+	}
+	return attrMap, scopes, extensions, nil
+}
+
+// extractBearerFormat returns the value of the optional @bearerFormat, like JWT, of a bearer security definition.
+func extractBearerFormat(lines []string) string {
+	for _, v := range lines {
+		securityAttr := strings.ToLower(strings.Split(v, " ")[0])
+		if securityAttr == "@bearerformat" {
+			return strings.TrimSpace(v[len(securityAttr):])
+		}
+		// next securityDefinitions
+		if strings.Index(securityAttr, "@securitydefinitions.") == 0 {
+			break
+		}
+	}
+	return ""
+}
+
+// securitySchemeBearer returns an HTTP bearer authentication. Swagger 2.0 has no type for it, so it's an api key
+// in the Authorization header marked by the x-scheme extension, which becomes type http in OpenAPI 3.
+func securitySchemeBearer(bearerFormat string) *spec.SecurityScheme {
+	securityScheme := spec.APIKeyAuth("Authorization", "header")
+	securityScheme.Extensions = spec.Extensions{"x-scheme": "bearer"}
+	if bearerFormat != "" {
+		securityScheme.Extensions["x-bearer-format"] = bearerFormat
+	}
+	return securityScheme
+}
+
+func securitySchemeOAuth2Application(tokenurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
+	securityScheme := spec.OAuth2Application(tokenurl)
+	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
+	for scope, description := range scopes {
+		securityScheme.AddScope(scope, description)
+	}
+	return securityScheme
+}
+
+func securitySchemeOAuth2Implicit(authorizationurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
+	securityScheme := spec.OAuth2Implicit(authorizationurl)
+	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
+	for scope, description := range scopes {
+		securityScheme.AddScope(scope, description)
+	}
+	return securityScheme
+}
+
+func securitySchemeOAuth2Password(tokenurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
+	securityScheme := spec.OAuth2Password(tokenurl)
+	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
+	for scope, description := range scopes {
+		securityScheme.AddScope(scope, description)
+	}
+	return securityScheme
+}
+
+func securitySchemeOAuth2AccessToken(authorizationurl, tokenurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
+	securityScheme := spec.OAuth2AccessToken(authorizationurl, tokenurl)
+	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
+	for scope, description := range scopes {
+		securityScheme.AddScope(scope, description)
+	}
+	return securityScheme
+}
+
+func handleSecuritySchemaExtensions(providedExtensions map[string]interface{}) spec.Extensions {
+	var extensions spec.Extensions
+	if len(providedExtensions) > 0 {
+		extensions = make(map[string]interface{}, len(providedExtensions))
+		for key, value := range providedExtensions {
+			extensions[key] = value
+		}
+	}
+	return extensions
+}
+
+// parseNamedExample parses the name and the json value of an example, eg: Pet {"name":"Rex"}
+func parseNamedExample(value string) (string, map[string]interface{}, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("example should format: name {json value}")
+	}
+	var example interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(value[len(fields[0]):])), &example); err != nil {
+		return "", nil, fmt.Errorf("example %s needs a valid json value", fields[0])
+	}
+	return fields[0], map[string]interface{}{"value": example}, nil
+}
+
+// namedExamples returns the examples declared by @example, keyed by name. They're kept in the x-examples
+// extension of the swagger 2.0 doc and become components/examples in OpenAPI 3.
+func (parser *Parser) namedExamples() map[string]interface{} {
+	if parser.swagger.Extensions == nil {
+		parser.swagger.Extensions = spec.Extensions{}
+	}
+	examples, ok := parser.swagger.Extensions["x-examples"].(map[string]interface{})
+	if !ok {
+		examples = map[string]interface{}{}
+		parser.swagger.Extensions["x-examples"] = examples
+	}
+	return examples
+}
+
+func getMarkdownForTag(tagName string, dirPath string) ([]byte, error) {
+	filesInfos, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fileInfo := range filesInfos {
+		if fileInfo.IsDir() {
+			continue
+		}
+		fileName := fileInfo.Name()
+
+		if !strings.Contains(fileName, ".md") {
+			continue
+		}
+
+		if strings.Contains(fileName, tagName) {
+			fullPath := filepath.Join(dirPath, fileName)
+			commentInfo, err := ioutil.ReadFile(fullPath)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to read markdown file %s error: %s ", fullPath, err)
+			}
+			return commentInfo, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to find markdown file for tag %s in the given directory", tagName)
+}
+
+func getScopeScheme(scope string) (string, error) {
+	scopeValue := scope[strings.Index(scope, "@scope."):]
+	if scopeValue == "" {
+		return "", fmt.Errorf("@scope is empty")
+	}
+	return scope[len("@scope."):], nil
+}
+
+func isExistsScope(scope string) (bool, error) {
+	s := strings.Fields(scope)
+	for _, v := range s {
+		if strings.Contains(v, "@scope.") {
+			if strings.Contains(v, ",") {
+				return false, fmt.Errorf("@scope can't use comma(,) get=" + v)
+			}
+		}
+	}
+	return strings.Contains(scope, "@scope."), nil
+}
+
+// getSchemes parses swagger schemes for given commentLine
+func getSchemes(commentLine string) []string {
+	attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
+	return strings.Split(strings.TrimSpace(commentLine[len(attribute):]), " ")
+}
+
+// ParseRouterAPIInfo parses router api info for given astFile
+func (parser *Parser) ParseRouterAPIInfo(fileName string, astFile *ast.File) error {
+	for _, astDescription := range astFile.Decls {
+		switch astDeclaration := astDescription.(type) {
+		case *ast.FuncDecl:
+			if astDeclaration.Doc != nil && astDeclaration.Doc.List != nil {
+				if parser.ParseFuncLocalTypes {
+					parser.localTypes = parser.funcLocalTypes(astFile, astDeclaration)
+				}
+				err := parser.parseRouterComment(fileName, astFile, astDeclaration.Doc, astDeclaration.Name.Name)
+				parser.localTypes = nil
+				if err != nil {
+					return err
+				}
+			}
+		case *ast.GenDecl:
+			if astDeclaration.Tok != token.VAR {
+				continue
+			}
+			for _, doc := range handlerMapDocs(astFile, astDeclaration) {
+				if err := parser.parseRouterComment(fileName, astFile, doc, ""); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// funcLocalTypes returns the types declared in the body of funcDecl.
+func (parser *Parser) funcLocalTypes(astFile *ast.File, funcDecl *ast.FuncDecl) map[string]*TypeSpecDef {
+	if funcDecl.Body == nil {
+		return nil
+	}
+
+	var pkgPath string
+	if info, ok := parser.packages.files[astFile]; ok {
+		pkgPath = info.PackagePath
+	}
+
+	localTypes := make(map[string]*TypeSpecDef)
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		genDecl, ok := node.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			return true
+		}
+		for _, astSpec := range genDecl.Specs {
+			if typeSpec, ok := astSpec.(*ast.TypeSpec); ok {
+				localTypes[typeSpec.Name.Name] = &TypeSpecDef{
+					PkgPath:    pkgPath,
+					File:       astFile,
+					TypeSpec:   typeSpec,
+					ParentSpec: funcDecl,
+				}
+			}
+		}
+		return false
+	})
+	return localTypes
+}
+
+// parseRouterComment parses the operation annotated by doc and registers it. funcName is the name of the
+// annotated function, or "" for a function literal.
+func (parser *Parser) parseRouterComment(fileName string, astFile *ast.File, doc *ast.CommentGroup, funcName string) error {
+	operation := NewOperation(parser, SetCodeExampleFilesDirectory(parser.codeExampleFilesDir)) //for per 'function' comment, create a new 'Operation' object
+	for _, comment := range sortOperationComments(doc.List) {
+		if err := operation.ParseComment(comment.Text, astFile); err != nil {
+			if parser.CollectDiagnostics {
+				// the operation is left out, the other ones are still parsed
+				parser.addDiagnostic(fileName, comment.Pos(), err)
+				return nil
+			}
+			return fmt.Errorf("ParseComment error in file %s :%+v", fileName, err)
+		}
+	}
+	if len(operation.Produces) == 0 {
+		produces, err := parser.packageProduces(astFile)
+		if err != nil {
+			return fmt.Errorf("ParseComment error in file %s :%+v", fileName, err)
+		}
+		operation.Produces = produces
+	}
+	if operation.Summary == "" && parser.SummaryFromFuncName && funcName != "" {
+		operation.Summary = funcNameSummary(funcName)
+	}
+	if parser.InlineResponseName != nil {
+		if err := parser.nameInlineResponses(operation); err != nil {
+			return fmt.Errorf("ParseComment error in file %s :%+v", fileName, err)
+		}
+	}
+	parser.addOperation(operation)
+	return nil
+}
+
+// nameInlineResponses turns the inline response objects of operation into definitions named by
+// InlineResponseName, which the responses refer to instead.
+func (parser *Parser) nameInlineResponses(operation *Operation) error {
+	if operation.Responses == nil {
+		return nil
+	}
+
+	name := func(status string, response *spec.Response) error {
+		schema := response.Schema
+		if schema == nil || schema.Ref.String() != "" || len(schema.Properties) == 0 && len(schema.AllOf) == 0 {
+			return nil
+		}
+		definition := parser.InlineResponseName(operation.ID, status)
+		if definition == "" {
+			return nil
+		}
+		if _, ok := parser.swagger.Definitions[definition]; ok {
+			return fmt.Errorf("name %s of the inline %s response is already taken", definition, status)
+		}
+		parser.swagger.Definitions[definition] = *schema
+		response.Schema = RefSchema(definition)
+		return nil
+	}
+
+	codes := make([]int, 0, len(operation.Responses.StatusCodeResponses))
+	for code := range operation.Responses.StatusCodeResponses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		response := operation.Responses.StatusCodeResponses[code]
+		if err := name(strconv.Itoa(code), &response); err != nil {
+			return err
+		}
+		operation.Responses.StatusCodeResponses[code] = response
+	}
+	if operation.Responses.Default != nil {
+		return name("default", operation.Responses.Default)
+	}
+	return nil
+}
+
+// funcNameSummary returns a summary derived from the camel case or snake case name of a function, its words
+// separated by spaces, lower cased except for the first one and acronyms like ID.
+func funcNameSummary(funcName string) string {
+	var words []string
+	for _, part := range strings.Split(funcName, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i <= len(runes); i++ {
+			if i < len(runes) && !(unicode.IsUpper(runes[i]) &&
+				(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				continue
+			}
+			if word := string(runes[start:i]); word != "" {
+				if len(runes[start:i]) == 1 || strings.ToUpper(word) != word {
+					word = strings.ToLower(word)
+				}
+				words = append(words, word)
+			}
+			start = i
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	first := []rune(words[0])
+	first[0] = unicode.ToUpper(first[0])
+	words[0] = string(first)
+	return strings.Join(words, " ")
+}
+
+// handlerMapDocs returns the comments annotating the function literals of map literals
+// in a var declaration, e.g. handlers registered as map[string]http.HandlerFunc{"/a": func(...){...}}.
+// A comment belongs to the entry it directly precedes within the literal and needs a @Router.
+func handlerMapDocs(astFile *ast.File, genDecl *ast.GenDecl) []*ast.CommentGroup {
+	var docs []*ast.CommentGroup
+	ast.Inspect(genDecl, func(node ast.Node) bool {
+		lit, ok := node.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		if _, ok := lit.Type.(*ast.MapType); !ok {
+			return true
+		}
+		prev := lit.Lbrace
+		for _, elt := range lit.Elts {
+			keyValue, ok := elt.(*ast.KeyValueExpr)
+			if ok {
+				if _, ok := keyValue.Value.(*ast.FuncLit); ok {
+					doc := commentBetween(astFile, prev, keyValue.Pos())
+					if doc != nil && strings.Contains(strings.ToLower(doc.Text()), "@router") {
+						docs = append(docs, doc)
+					}
+				}
+			}
+			prev = elt.End()
+		}
+		return true
+	})
+	return docs
+}
+
+// commentBetween returns the last comment group of astFile lying between from and to.
+func commentBetween(astFile *ast.File, from, to token.Pos) *ast.CommentGroup {
+	var doc *ast.CommentGroup
+	for _, comment := range astFile.Comments {
+		if comment.Pos() > from && comment.End() <= to {
+			doc = comment
+		}
+	}
+	return doc
+}
+
+// packageProduces returns the mime types of a @Produce declared in the package doc comments of the package
+// of astFile, which is parsed once per package. Files of the package declaring other mime types are an error.
+func (parser *Parser) packageProduces(astFile *ast.File) ([]string, error) {
+	files := map[string]*ast.File{"": astFile}
+	pkgPath := ""
+	if info, ok := parser.packages.files[astFile]; ok {
+		if pkg, ok := parser.packages.packages[info.PackagePath]; ok {
+			files, pkgPath = pkg.Files, info.PackagePath
+			if produces, ok := parser.producesByPackage[pkgPath]; ok {
+				return append([]string(nil), produces...), nil
+			}
+		}
+	}
+
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	var produces []string
+	declaredBy := ""
+	for _, fileName := range fileNames {
+		file := files[fileName]
+		if file.Doc == nil {
+			continue
+		}
+		var fileProduces []string
+		for _, commentLine := range commentLines(file.Doc.Text()) {
+			fields := strings.Fields(commentLine)
+			if len(fields) < 2 || !strings.EqualFold(fields[0], "@produce") {
+				continue
+			}
+			if err := parseMimeTypeList(strings.TrimSpace(commentLine[len(fields[0]):]), &fileProduces, "%v produce type can't be accepted"); err != nil {
+				return nil, err
 			}
-			scopes[scopScheme] = v[len(securityAttr):]
 		}
-		if securityAttr == "@x-tokenname" {
-			extensions["x-tokenName"] = strings.TrimSpace(v[len(securityAttr):])
+		if fileProduces == nil {
+			continue
 		}
-		// next securityDefinitions
-		if strings.Index(securityAttr, "@securitydefinitions.") == 0 {
-			break
+		if declaredBy != "" && !reflect.DeepEqual(produces, fileProduces) {
+			return nil, fmt.Errorf("conflicting @Produce in the package doc comments of %s and %s", declaredBy, fileName)
 		}
+		produces, declaredBy = fileProduces, fileName
 	}
-	if len(attrMap) != len(search) {
-		return nil, nil, nil, fmt.Errorf("%s is %v required", context, search)
+
+	if pkgPath != "" {
+		if parser.producesByPackage == nil {
+			parser.producesByPackage = make(map[string][]string)
+		}
+		parser.producesByPackage[pkgPath] = append([]string(nil), produces...)
 	}
-	return attrMap, scopes, extensions, nil
+	return produces, nil
 }
 
-func securitySchemeOAuth2Application(tokenurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
-	securityScheme := spec.OAuth2Application(tokenurl)
-	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
-	for scope, description := range scopes {
-		securityScheme.AddScope(scope, description)
+// addOperation registers operation at its path and http method. An operation with several
+// @Router annotations is registered once per router, an explicit @ID gets the router's
+// position as suffix from the second router on, so the ids stay unique and stable.
+func (parser *Parser) addOperation(operation *Operation) {
+	if len(operation.RouterProperties) <= 1 {
+		parser.addPathOperation(operation.Path, operation.HTTPMethod, &operation.Operation)
+		return
 	}
-	return securityScheme
-}
 
-func securitySchemeOAuth2Implicit(authorizationurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
-	securityScheme := spec.OAuth2Implicit(authorizationurl)
-	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
-	for scope, description := range scopes {
-		securityScheme.AddScope(scope, description)
+	for i, route := range operation.RouterProperties {
+		op := operation.Operation
+		if op.ID != "" && i > 0 {
+			op.ID = fmt.Sprintf("%s-%d", op.ID, i+1)
+		}
+		parser.addPathOperation(route.Path, route.HTTPMethod, &op)
 	}
-	return securityScheme
 }
 
-func securitySchemeOAuth2Password(tokenurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
-	securityScheme := spec.OAuth2Password(tokenurl)
-	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
-	for scope, description := range scopes {
-		securityScheme.AddScope(scope, description)
-	}
-	return securityScheme
+func (parser *Parser) addPathOperation(path, httpMethod string, operation *spec.Operation) {
+	path = parser.trimRouterPrefix(path)
+
+	var pathItem spec.PathItem
+	var ok bool
+
+	if pathItem, ok = parser.swagger.Paths.Paths[path]; !ok {
+		pathItem = spec.PathItem{}
+	}
+	switch strings.ToUpper(httpMethod) {
+	case http.MethodGet:
+		pathItem.Get = operation
+	case http.MethodPost:
+		pathItem.Post = operation
+	case http.MethodDelete:
+		pathItem.Delete = operation
+	case http.MethodPut:
+		pathItem.Put = operation
+	case http.MethodPatch:
+		pathItem.Patch = operation
+	case http.MethodHead:
+		pathItem.Head = operation
+	case http.MethodOptions:
+		pathItem.Options = operation
+	}
+
+	parser.swagger.Paths.Paths[path] = pathItem
 }
 
-func securitySchemeOAuth2AccessToken(authorizationurl, tokenurl string, scopes map[string]string, extensions map[string]interface{}) *spec.SecurityScheme {
-	securityScheme := spec.OAuth2AccessToken(authorizationurl, tokenurl)
-	securityScheme.VendorExtensible.Extensions = handleSecuritySchemaExtensions(extensions)
-	for scope, description := range scopes {
-		securityScheme.AddScope(scope, description)
+// trimRouterPrefix returns path without the RouterPrefixTrim, or / if it's the prefix itself.
+func (parser *Parser) trimRouterPrefix(path string) string {
+	prefix := strings.TrimSuffix(parser.RouterPrefixTrim, "/")
+	if prefix == "" || !strings.HasPrefix(path, prefix) {
+		return path
 	}
-	return securityScheme
+	trimmed := path[len(prefix):]
+	if trimmed == "" {
+		return "/"
+	}
+	if trimmed[0] != '/' {
+		return path
+	}
+	return trimmed
 }
 
-func handleSecuritySchemaExtensions(providedExtensions map[string]interface{}) spec.Extensions {
-	var extensions spec.Extensions
-	if len(providedExtensions) > 0 {
-		extensions = make(map[string]interface{}, len(providedExtensions))
-		for key, value := range providedExtensions {
-			extensions[key] = value
+// addMissingTags appends the tags used by operations, which aren't declared yet, to the root tags in alphabetical order.
+func (parser *Parser) addMissingTags() {
+	declared := map[string]bool{}
+	for _, tag := range parser.swagger.Tags {
+		declared[tag.Name] = true
+	}
+
+	var missing []string
+	for _, pathItem := range parser.swagger.Paths.Paths {
+		for _, op := range []*spec.Operation{pathItem.Get, pathItem.Post, pathItem.Delete, pathItem.Put,
+			pathItem.Patch, pathItem.Head, pathItem.Options} {
+			if op == nil {
+				continue
+			}
+			for _, tag := range op.Tags {
+				if !declared[tag] {
+					declared[tag] = true
+					missing = append(missing, tag)
+				}
+			}
 		}
 	}
-	return extensions
-}
 
-func getMarkdownForTag(tagName string, dirPath string) ([]byte, error) {
-	filesInfos, err := ioutil.ReadDir(dirPath)
-	if err != nil {
-		return nil, err
+	sort.Strings(missing)
+	for _, tag := range missing {
+		parser.swagger.Tags = append(parser.swagger.Tags, spec.NewTag(tag, "", nil))
 	}
+}
 
-	for _, fileInfo := range filesInfos {
-		if fileInfo.IsDir() {
-			continue
+// findOperationByID returns the already registered operation with the given operationId.
+func (parser *Parser) findOperationByID(id string) *spec.Operation {
+	for _, pathItem := range parser.swagger.Paths.Paths {
+		for _, op := range []*spec.Operation{pathItem.Get, pathItem.Post, pathItem.Delete, pathItem.Put,
+			pathItem.Patch, pathItem.Head, pathItem.Options} {
+			if op != nil && op.ID == id {
+				return op
+			}
 		}
-		fileName := fileInfo.Name()
+	}
+	return nil
+}
 
-		if !strings.Contains(fileName, ".md") {
+// ParseCommentSource parses annotations from a non-Go source like a template file.
+// Consecutive lines starting with an annotation (optionally behind //, # or {{/* markers)
+// form one block. A block with @Router adds a new operation, a block without @Router
+// extends the operation with the same @ID. Types must be referenced by their full name.
+func (parser *Parser) ParseCommentSource(fileName string, src []byte) error {
+	var block []string
+	for _, line := range commentLines(string(src)) {
+		line = strings.TrimSpace(line)
+		for _, marker := range []string{"{{/*", "//", "#"} {
+			line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+		}
+		line = strings.TrimSpace(strings.TrimSuffix(line, "*/}}"))
+		if strings.HasPrefix(line, "@") {
+			block = append(block, line)
 			continue
 		}
-
-		if strings.Contains(fileName, tagName) {
-			fullPath := filepath.Join(dirPath, fileName)
-			commentInfo, err := ioutil.ReadFile(fullPath)
-			if err != nil {
-				return nil, fmt.Errorf("Failed to read markdown file %s error: %s ", fullPath, err)
-			}
-			return commentInfo, nil
+		if err := parser.parseCommentSourceBlock(fileName, block); err != nil {
+			return err
 		}
+		block = nil
 	}
-	return nil, fmt.Errorf("Unable to find markdown file for tag %s in the given directory", tagName)
+
+	return parser.parseCommentSourceBlock(fileName, block)
 }
 
-func getScopeScheme(scope string) (string, error) {
-	scopeValue := scope[strings.Index(scope, "@scope."):]
-	if scopeValue == "" {
-		return "", fmt.Errorf("@scope is empty")
+func (parser *Parser) parseCommentSourceBlock(fileName string, block []string) error {
+	if len(block) == 0 {
+		return nil
 	}
-	return scope[len("@scope."):], nil
-}
 
-func isExistsScope(scope string) (bool, error) {
-	s := strings.Fields(scope)
-	for _, v := range s {
-		if strings.Contains(v, "@scope.") {
-			if strings.Contains(v, ",") {
-				return false, fmt.Errorf("@scope can't use comma(,) get=" + v)
-			}
+	operation := NewOperation(parser, SetCodeExampleFilesDirectory(parser.codeExampleFilesDir))
+	var existing *spec.Operation
+	hasRouter := false
+	for _, line := range block {
+		attribute := strings.ToLower(strings.Fields(line)[0])
+		if attribute == "@router" {
+			hasRouter = true
+		} else if attribute == "@id" {
+			existing = parser.findOperationByID(strings.TrimSpace(line[len(attribute):]))
 		}
 	}
-	return strings.Contains(scope, "@scope."), nil
-}
+	if !hasRouter {
+		if existing == nil {
+			return fmt.Errorf("annotations in file %s have neither @Router nor @ID of a known operation: %s", fileName, block[0])
+		}
+		operation.Operation = *existing
+	}
 
-// getSchemes parses swagger schemes for given commentLine
-func getSchemes(commentLine string) []string {
-	attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
-	return strings.Split(strings.TrimSpace(commentLine[len(attribute):]), " ")
-}
+	comments := make([]*ast.Comment, 0, len(block))
+	for _, line := range block {
+		comments = append(comments, &ast.Comment{Text: "// " + line})
+	}
+	for _, comment := range sortOperationComments(comments) {
+		if err := operation.ParseComment(comment.Text, nil); err != nil {
+			return fmt.Errorf("ParseComment error in file %s :%+v", fileName, err)
+		}
+	}
 
-// ParseRouterAPIInfo parses router api info for given astFile
-func (parser *Parser) ParseRouterAPIInfo(fileName string, astFile *ast.File) error {
-	for _, astDescription := range astFile.Decls {
-		switch astDeclaration := astDescription.(type) {
-		case *ast.FuncDecl:
-			if astDeclaration.Doc != nil && astDeclaration.Doc.List != nil {
-				operation := NewOperation(parser, SetCodeExampleFilesDirectory(parser.codeExampleFilesDir)) //for per 'function' comment, create a new 'Operation' object
-				for _, comment := range astDeclaration.Doc.List {
-					if err := operation.ParseComment(comment.Text, astFile); err != nil {
-						return fmt.Errorf("ParseComment error in file %s :%+v", fileName, err)
-					}
-				}
-				var pathItem spec.PathItem
-				var ok bool
+	if hasRouter {
+		parser.addOperation(operation)
+	} else {
+		*existing = operation.Operation
+	}
 
-				if pathItem, ok = parser.swagger.Paths.Paths[operation.Path]; !ok {
-					pathItem = spec.PathItem{}
-				}
-				switch strings.ToUpper(operation.HTTPMethod) {
-				case http.MethodGet:
-					pathItem.Get = &operation.Operation
-				case http.MethodPost:
-					pathItem.Post = &operation.Operation
-				case http.MethodDelete:
-					pathItem.Delete = &operation.Operation
-				case http.MethodPut:
-					pathItem.Put = &operation.Operation
-				case http.MethodPatch:
-					pathItem.Patch = &operation.Operation
-				case http.MethodHead:
-					pathItem.Head = &operation.Operation
-				case http.MethodOptions:
-					pathItem.Options = &operation.Operation
-				}
+	return nil
+}
 
-				parser.swagger.Paths.Paths[operation.Path] = pathItem
+// parseCommentSources parses the registered comment sources, relative patterns are resolved against searchDir.
+func (parser *Parser) parseCommentSources(searchDir string) error {
+	for _, pattern := range parser.CommentSources {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(searchDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		sort.Strings(matches)
+		for _, fileName := range matches {
+			src, err := ioutil.ReadFile(fileName)
+			if err != nil {
+				return err
+			}
+			if err := parser.ParseCommentSource(fileName, src); err != nil {
+				return err
 			}
 		}
 	}
@@ -624,6 +1699,26 @@ func (parser *Parser) ParseRouterAPIInfo(fileName string, astFile *ast.File) err
 	return nil
 }
 
+// sortOperationComments moves the annotations which depend on others to the end, so the
+// order of annotations inside a comment block doesn't change the resulting operation.
+// @Header needs the responses it's attached to and @x-codeSamples file needs the @Summary.
+func sortOperationComments(comments []*ast.Comment) []*ast.Comment {
+	sorted := make([]*ast.Comment, 0, len(comments))
+	var dependent []*ast.Comment
+	for _, comment := range comments {
+		fields := strings.Fields(strings.TrimLeft(comment.Text, "/"))
+		if len(fields) > 0 {
+			switch strings.ToLower(fields[0]) {
+			case "@header", "@link", "@x-codesamples":
+				dependent = append(dependent, comment)
+				continue
+			}
+		}
+		sorted = append(sorted, comment)
+	}
+	return append(sorted, dependent...)
+}
+
 func convertFromSpecificToPrimitive(typeName string) (string, error) {
 	name := typeName
 	if strings.ContainsRune(name, '.') {
@@ -639,20 +1734,91 @@ func convertFromSpecificToPrimitive(typeName string) (string, error) {
 }
 
 func (parser *Parser) getTypeSchema(typeName string, file *ast.File, ref bool) (*spec.Schema, error) {
+	parser.registerTypeOptions()
+	if arg, ok := parser.typeArgs[typeName]; ok {
+		return parser.parseTypeArg(arg, ref)
+	}
+
 	if IsGolangPrimitiveType(typeName) {
+		if parser.Int64AsString && (typeName == "int64" || typeName == "uint64") {
+			schema := PrimitiveSchema(STRING)
+			schema.Format = typeName
+			return schema, nil
+		}
 		return PrimitiveSchema(TransToValidSchemeType(typeName)), nil
 	}
 
+	if typeSpecDef := parser.packages.findTypeOverride(typeName, file); typeSpecDef != nil {
+		return parser.getTypeSpecSchema(typeSpecDef, ref)
+	}
+
+	if typeName == "any" || typeName == "error" {
+		// like interface{}, the value of an interface may be of any type
+		return PrimitiveSchema(OBJECT), nil
+	}
+
 	if schemaType, err := convertFromSpecificToPrimitive(typeName); err == nil {
 		return PrimitiveSchema(schemaType), nil
 	}
 
+	if strings.HasSuffix(typeName, "]") {
+		// an instantiated generic type like model.Page[model.User]
+		if expr, err := goparser.ParseExpr(typeName); err == nil {
+			if genericType, args, ok := genericTypeArgs(expr); ok {
+				return parser.getGenericTypeSchema(file, genericType, args, ref)
+			}
+		}
+	}
+
+	if len(parser.localTypes) > 0 {
+		if typeSpecDef, ok := parser.localTypes[strings.TrimPrefix(typeName, file.Name.Name+".")]; ok {
+			return parser.getTypeSpecSchema(typeSpecDef, ref)
+		}
+	}
+
 	typeSpecDef := parser.packages.FindTypeSpec(typeName, file)
+	if typeSpecDef == nil {
+		if pkgPaths := parser.packages.ambiguousPackagePaths(typeName, file); len(pkgPaths) > 1 {
+			return nil, fmt.Errorf("ambiguous type definition: %s is declared in packages %s, import the one meant",
+				typeName, strings.Join(pkgPaths, ", "))
+		}
+		if parser.collectDependency(typeName, file) {
+			typeSpecDef = parser.packages.FindTypeSpec(typeName, file)
+		}
+	}
 	if typeSpecDef == nil {
 		parser.packages.FindTypeSpec(typeName, file) // uncomment for debugging
 		return nil, fmt.Errorf("cannot find type definition: %s", typeName)
 	}
 
+	return parser.getTypeSpecSchema(typeSpecDef, ref)
+}
+
+// getTypeSpecSchema returns the schema of a found type definition, a reference to it for objects if ref is true.
+func (parser *Parser) getTypeSpecSchema(typeSpecDef *TypeSpecDef, ref bool) (*spec.Schema, error) {
+	parser.registerTypeOptions()
+	if typeSpecDef.File != nil {
+		if override, ok := parser.packages.typeOverrides[typeSpecDef.FullName()]; ok &&
+			(override.PkgPath == "" || override.PkgPath == typeSpecDef.PkgPath) {
+			// the type is referred to by a name, like Status in its own package, other than the one registered
+			return parser.getTypeSpecSchema(override, ref)
+		}
+	}
+	if star, ok := pointerTypeSpec(typeSpecDef); ok {
+		// type Foo *Bar is marshaled like Bar, so it refers to the definition of Bar instead of copying it
+		return parser.parseTypeExpr(typeSpecDef.File, star.X, ref)
+	}
+	if genericType, args, ok := genericAliasTypeSpec(typeSpecDef); ok {
+		// type Foo = Page[Bar] is the instantiation itself, so it refers to its definition instead of copying it.
+		// The type arguments are written in the file of the alias, out of the scope of the type being parsed now.
+		typeArgs := parser.typeArgs
+		parser.typeArgs = nil
+		defer func() {
+			parser.typeArgs = typeArgs
+		}()
+		return parser.getGenericTypeSchema(typeSpecDef.File, genericType, args, ref)
+	}
+
 	schema, ok := parser.parsedSchemas[typeSpecDef]
 	if !ok {
 		var err error
@@ -670,9 +1836,31 @@ func (parser *Parser) getTypeSchema(typeName string, file *ast.File, ref bool) (
 	if ref && len(schema.Schema.Type) > 0 && schema.Schema.Type[0] == OBJECT {
 		return parser.getRefTypeSchema(typeSpecDef, schema), nil
 	}
+	if ref && parser.EnumRefs && len(schema.Schema.Enum) > 0 && len(typeSpecDef.Enums) > 0 {
+		return parser.getRefTypeSchema(typeSpecDef, schema), nil
+	}
 	return schema.Schema, nil
 }
 
+// pointerTypeSpec returns the pointer type a non-generic type definition, like type Foo *Bar or
+// type Foo = *Bar, is declared as.
+func pointerTypeSpec(typeSpecDef *TypeSpecDef) (*ast.StarExpr, bool) {
+	if typeSpecDef.File == nil || typeSpecDef.TypeSpec == nil || len(typeSpecDef.TypeParams) > 0 {
+		return nil, false
+	}
+	star, ok := typeSpecDef.TypeSpec.Type.(*ast.StarExpr)
+	return star, ok
+}
+
+// genericAliasTypeSpec returns the generic type and the type arguments of an alias of an instantiation,
+// like type UserPage = Page[User].
+func genericAliasTypeSpec(typeSpecDef *TypeSpecDef) (ast.Expr, []ast.Expr, bool) {
+	if typeSpecDef.File == nil || typeSpecDef.TypeSpec == nil || !typeSpecDef.TypeSpec.Assign.IsValid() {
+		return nil, nil, false
+	}
+	return genericTypeArgs(typeSpecDef.TypeSpec.Type)
+}
+
 func (parser *Parser) renameRefSchemas() {
 	if len(parser.toBeRenamedSchemas) == 0 {
 		return
@@ -706,7 +1894,10 @@ func (parser *Parser) renameSchema(name, pkgPath string) string {
 }
 
 func (parser *Parser) getRefTypeSchema(typeSpecDef *TypeSpecDef, schema *Schema) *spec.Schema {
-	if _, ok := parser.outputSchemas[typeSpecDef]; !ok {
+	if output, ok := parser.outputSchemas[typeSpecDef]; ok {
+		// the definition may have been output, and renamed, while the type was still being parsed
+		schema = output
+	} else {
 		if existSchema, ok := parser.existSchemaNames[schema.Name]; ok {
 			//store the first one to be renamed after parsing over
 			if _, ok = parser.toBeRenamedSchemas[existSchema.Name]; !ok {
@@ -731,28 +1922,35 @@ func (parser *Parser) getRefTypeSchema(typeSpecDef *TypeSpecDef, schema *Schema)
 	return refSchema
 }
 
-func (parser *Parser) isInStructStack(typeSpecDef *TypeSpecDef) bool {
-	for _, specDef := range parser.structStack {
-		if typeSpecDef == specDef {
-			return true
-		}
-	}
-	return false
-}
-
 // ParseDefinition parses given type spec that corresponds to the type under
 // given name and package, and populates swagger schema definitions registry
 // with a schema for the given type
 func (parser *Parser) ParseDefinition(typeSpecDef *TypeSpecDef) (*Schema, error) {
 	typeName := typeSpecDef.FullName()
-	refTypeName := TypeDocName(typeName, typeSpecDef.TypeSpec)
+	refTypeName := typeDocName(typeSpecDef)
+	named := typeNameAnnotation(typeSpecDef) != ""
+	if typeSpecDef.ParentSpec != nil && !named {
+		// local types of different functions may share their name
+		refTypeName = typeName
+	}
+	if len(typeSpecDef.TypeArgs) > 0 {
+		refTypeName = genericDocName(refTypeName, typeSpecDef.TypeArgs)
+	}
+
+	// the type parameters of another generic type are out of scope
+	typeArgs := parser.typeArgs
+	parser.typeArgs = typeSpecDef.typeArgs
+	defer func() {
+		parser.typeArgs = typeArgs
+	}()
 
 	if schema, ok := parser.parsedSchemas[typeSpecDef]; ok {
 		Println("Skipping '" + typeName + "', already parsed.")
 		return schema, nil
 	}
 
-	if parser.isInStructStack(typeSpecDef) {
+	pathName := typeSpecDef.pathName()
+	if parser.parsingTypes[pathName] {
 		Println("Skipping '" + typeName + "', recursion detected.")
 		return &Schema{
 				Name:    refTypeName,
@@ -760,7 +1958,17 @@ func (parser *Parser) ParseDefinition(typeSpecDef *TypeSpecDef) (*Schema, error)
 				Schema:  PrimitiveSchema(OBJECT)},
 			ErrRecursiveParseStruct
 	}
-	parser.structStack = append(parser.structStack, typeSpecDef)
+	if named {
+		// a name set by @name isn't renamed on a collision, as it was chosen on purpose
+		if claimed, ok := parser.namedDefinitions[refTypeName]; ok && claimed.pathName() != pathName {
+			return nil, fmt.Errorf("%s at %s and %s at %s are both named %s by @name",
+				claimed.FullName(), parser.typePosition(claimed), typeName, parser.typePosition(typeSpecDef), refTypeName)
+		}
+		parser.namedDefinitions[refTypeName] = typeSpecDef
+	}
+
+	parser.parsingTypes[pathName] = true
+	defer delete(parser.parsingTypes, pathName)
 
 	Println("Generating " + typeName)
 
@@ -768,6 +1976,15 @@ func (parser *Parser) ParseDefinition(typeSpecDef *TypeSpecDef) (*Schema, error)
 	if err != nil {
 		return nil, err
 	}
+	if len(typeSpecDef.Enums) > 0 && len(schema.Type) > 0 && IsPrimitiveType(schema.Type[0]) {
+		schema = EnumSchema(schema, typeSpecDef.Enums)
+	}
+	if description := typeDescription(typeSpecDef); description != "" {
+		// copy, as an alias of another type shares its schema
+		described := *schema
+		described.Description = description
+		schema = &described
+	}
 	s := &Schema{Name: refTypeName, PkgPath: typeSpecDef.PkgPath, Schema: schema}
 	parser.parsedSchemas[typeSpecDef] = s
 
@@ -779,6 +1996,68 @@ func (parser *Parser) ParseDefinition(typeSpecDef *TypeSpecDef) (*Schema, error)
 	return s, nil
 }
 
+// typeDoc returns the doc comment of a type definition.
+func typeDoc(typeSpecDef *TypeSpecDef) *ast.CommentGroup {
+	doc := typeSpecDef.TypeSpec.Doc
+	if doc == nil && typeSpecDef.File != nil {
+		// the doc comment of type Foo struct{} belongs to its declaration
+		for _, decl := range typeSpecDef.File.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && len(genDecl.Specs) == 1 && genDecl.Specs[0] == typeSpecDef.TypeSpec {
+				doc = genDecl.Doc
+				break
+			}
+		}
+	}
+	return doc
+}
+
+// typeNameAnnotation returns the name set by a @name annotation of a type definition, which is
+// either in its doc comment or in its line comment, like type Foo struct{} //@name Bar.
+func typeNameAnnotation(typeSpecDef *TypeSpecDef) string {
+	if name := nameAnnotation(typeSpecDef.TypeSpec.Comment); name != "" {
+		return name
+	}
+	return nameAnnotation(typeDoc(typeSpecDef))
+}
+
+// typeDocName returns the name of the definition of a type in doc, its full name unless it's set by @name.
+func typeDocName(typeSpecDef *TypeSpecDef) string {
+	if name := typeNameAnnotation(typeSpecDef); name != "" {
+		return name
+	}
+	return TypeDocName(typeSpecDef.FullName(), typeSpecDef.TypeSpec)
+}
+
+// typePosition returns the position of the declaration of a type, like model/user.go:12:6, or its file if the
+// position is unknown.
+func (parser *Parser) typePosition(typeSpecDef *TypeSpecDef) string {
+	fileName := ""
+	if info, ok := parser.packages.files[typeSpecDef.File]; ok {
+		fileName = info.Path
+	}
+	if position := parser.fileSet.Position(typeSpecDef.TypeSpec.Pos()); position.IsValid() && position.Filename == fileName {
+		return position.String()
+	}
+	return fileName
+}
+
+// typeDescription returns the @Description annotations of the doc comment of a type definition.
+func typeDescription(typeSpecDef *TypeSpecDef) string {
+	doc := typeDoc(typeSpecDef)
+	if doc == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, commentLine := range commentLines(doc.Text()) {
+		fields := strings.Fields(commentLine)
+		if len(fields) > 0 && strings.EqualFold(fields[0], "@description") {
+			lines = append(lines, strings.TrimSpace(strings.TrimSpace(commentLine)[len(fields[0]):]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func fullTypeName(pkgName, typeName string) string {
 	if pkgName != "" {
 		return pkgName + "." + typeName
@@ -809,14 +2088,30 @@ func (parser *Parser) parseTypeExpr(file *ast.File, typeExpr ast.Expr, ref bool)
 		}
 	// type Foo []Baz
 	case *ast.ArrayType:
+		if elt, ok := expr.Elt.(*ast.Ident); ok && expr.Len == nil && (elt.Name == "byte" || elt.Name == "uint8") {
+			// encoding/json marshals []byte as a base64 string
+			schema := PrimitiveSchema(STRING)
+			schema.Format = "byte"
+			return schema, nil
+		}
+		if isEmptyInterface(expr.Elt) {
+			// type Foo []interface{} holds items of any type
+			return spec.ArrayProperty(&spec.Schema{}), nil
+		}
 		itemSchema, err := parser.parseTypeExpr(file, expr.Elt, true)
 		if err != nil {
 			return nil, err
 		}
+		if _, ok := expr.Elt.(*ast.StarExpr); ok && parser.OpenAPI3 {
+			// type Foo []*Baz holds nil items
+			nullableSchema := *itemSchema
+			nullableSchema.AddExtension("x-nullable", true)
+			itemSchema = &nullableSchema
+		}
 		return spec.ArrayProperty(itemSchema), nil
 	// type Foo map[string]Bar
 	case *ast.MapType:
-		if _, ok := expr.Value.(*ast.InterfaceType); ok {
+		if _, ok := expr.Value.(*ast.InterfaceType); ok || isEmptyInterface(expr.Value) {
 			return spec.MapProperty(nil), nil
 		}
 		schema, err := parser.parseTypeExpr(file, expr.Value, true)
@@ -824,10 +2119,20 @@ func (parser *Parser) parseTypeExpr(file *ast.File, typeExpr ast.Expr, ref bool)
 			return nil, err
 		}
 		return spec.MapProperty(schema), nil
+	// type Foo interface {...}
+	case *ast.InterfaceType:
+		// the value may be of any type implementing the interface, so it's a free-form object
+		return PrimitiveSchema(OBJECT), nil
 	case *ast.FuncType:
 		return nil, ErrFuncTypeField
+	case *ast.ChanType:
+		return nil, ErrChanTypeField
 	// ...
 	default:
+		// type Foo Page[Baz]
+		if genericType, args, ok := genericTypeArgs(expr); ok {
+			return parser.getGenericTypeSchema(file, genericType, args, ref)
+		}
 		Printf("Type definition of type '%T' is not supported yet. Using 'object' instead.\n", typeExpr)
 	}
 
@@ -837,52 +2142,210 @@ func (parser *Parser) parseTypeExpr(file *ast.File, typeExpr ast.Expr, ref bool)
 func (parser *Parser) parseStruct(file *ast.File, fields *ast.FieldList) (*spec.Schema, error) {
 
 	required := make([]string, 0)
+	var promotedRequired []string
 	properties := make(map[string]spec.Schema)
+	// properties promoted from embedded structs, which are shadowed by the ones the struct declares itself
+	promoted := make(map[string]spec.Schema)
+	// references to the definitions of embedded structs composed by allOf
+	var embedded []spec.Schema
 	for _, field := range fields.List {
+		field = parser.namedEmbeddedField(file, field)
+		if ref := parser.embeddedRef(file, field); ref != nil {
+			embedded = append(embedded, *ref)
+			continue
+		}
 		fieldProps, requiredFromAnon, err := parser.parseStructField(file, field)
-		if err == ErrFuncTypeField {
+		if (err == ErrFuncTypeField || err == ErrChanTypeField) && !parser.StrictFieldTypes {
+			// func and chan values can't be serialized, so they never show up in the payload
 			continue
 		} else if err != nil {
 			return nil, err
 		} else if len(fieldProps) == 0 {
 			continue
 		}
+		if field.Names == nil {
+			promotedRequired = append(promotedRequired, requiredFromAnon...)
+			for k, v := range fieldProps {
+				promoted[k] = v
+			}
+			continue
+		}
 		required = append(required, requiredFromAnon...)
 		for k, v := range fieldProps {
 			properties[k] = v
 		}
 	}
+	for _, name := range promotedRequired {
+		if _, ok := properties[name]; !ok {
+			required = append(required, name)
+		}
+	}
+	for k, v := range promoted {
+		if _, ok := properties[k]; !ok {
+			properties[k] = v
+		}
+	}
+
+	sort.Strings(required)
+
+	schema := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:       []string{OBJECT},
+			Properties: properties,
+			Required:   required,
+		}}
+	if len(embedded) == 0 {
+		return schema, nil
+	}
+	// still an object, so it's referred to like any struct
+	if len(properties) == 0 {
+		return &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{OBJECT}, AllOf: embedded}}, nil
+	}
+	return &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{OBJECT}, AllOf: append(embedded, *schema)}}, nil
+}
+
+// embeddedRef returns the reference to the definition of the struct embedded by field in EmbeddedAllOf mode,
+// or nil if the field isn't embedded or its type has no definition, then its properties are promoted.
+func (parser *Parser) embeddedRef(file *ast.File, field *ast.Field) *spec.Schema {
+	if parser.EmbeddedMode != EmbeddedAllOf || field.Names != nil || isContextType(file, field.Type) {
+		return nil
+	}
+	if field.Tag != nil {
+		skip, ok := reflect.StructTag(strings.ReplaceAll(field.Tag.Value, "`", "")).Lookup("swaggerignore")
+		if ok && strings.EqualFold(skip, "true") {
+			return nil
+		}
+	}
+
+	typeName, err := getFieldType(field.Type)
+	if err != nil {
+		return nil
+	}
+	schema, err := parser.getTypeSchema(typeName, file, true)
+	if err != nil || schema.Ref.String() == "" {
+		return nil
+	}
+	return schema
+}
+
+// namedEmbeddedField returns an embedded field as a field named after its type, if encoding/json doesn't promote
+// the fields of the embedded type. That's the case for interfaces and for a name given by the json tag.
+func (parser *Parser) namedEmbeddedField(file *ast.File, field *ast.Field) *ast.Field {
+	if field.Names != nil {
+		return field
+	}
+
+	typeExpr := field.Type
+	if starExpr, ok := typeExpr.(*ast.StarExpr); ok {
+		typeExpr = starExpr.X
+	}
+	var name string
+	switch expr := typeExpr.(type) {
+	case *ast.Ident:
+		name = expr.Name
+	case *ast.SelectorExpr:
+		name = expr.Sel.Name
+	default:
+		return field
+	}
+
+	jsonName := ""
+	if field.Tag != nil {
+		jsonTag := reflect.StructTag(strings.ReplaceAll(field.Tag.Value, "`", "")).Get("json")
+		jsonName = strings.TrimSpace(strings.Split(jsonTag, ",")[0])
+	}
+	if jsonName == "" && !parser.isInterfaceType(file, typeExpr) {
+		return field
+	}
+
+	return &ast.Field{
+		Doc:     field.Doc,
+		Names:   []*ast.Ident{ast.NewIdent(name)},
+		Type:    field.Type,
+		Tag:     field.Tag,
+		Comment: field.Comment,
+	}
+}
 
-	sort.Strings(required)
+// isEmptyInterface whether expr is interface{} or its alias any.
+func isEmptyInterface(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.InterfaceType:
+		return expr.Methods == nil || len(expr.Methods.List) == 0
+	case *ast.Ident:
+		return expr.Name == "any"
+	}
+	return false
+}
 
-	return &spec.Schema{
-		SchemaProps: spec.SchemaProps{
-			Type:       []string{OBJECT},
-			Properties: properties,
-			Required:   required,
-		}}, nil
+// isInterfaceType whether typeExpr, used in file, is the error type or a parsed interface type.
+func (parser *Parser) isInterfaceType(file *ast.File, typeExpr ast.Expr) bool {
+	typeName := types.ExprString(typeExpr)
+	if typeName == "error" {
+		return true
+	}
+	typeSpecDef := parser.packages.FindTypeSpec(typeName, file)
+	if typeSpecDef == nil || typeSpecDef.TypeSpec == nil {
+		return false
+	}
+	_, ok := typeSpecDef.TypeSpec.Type.(*ast.InterfaceType)
+	return ok
+}
+
+// fakerExamples maps the kinds of faker-like tags to representative example values.
+var fakerExamples = map[string]string{
+	"email":           "john.doe@example.com",
+	"uuid":            "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uuid_digit":      "3fa85f6457174562b3fc2c963f66afa6",
+	"uuid_hyphenated": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"name":            "John Doe",
+	"first_name":      "John",
+	"last_name":       "Doe",
+	"username":        "johndoe",
+	"phone_number":    "+1-202-555-0143",
+	"url":             "https://example.com",
+	"domain_name":     "example.com",
+	"ipv4":            "192.0.2.1",
+	"ipv6":            "2001:db8::1",
+	"word":            "lorem",
+	"sentence":        "Lorem ipsum dolor sit amet.",
+	"date":            "2021-01-02",
+	"timestamp":       "2021-01-02 15:04:05",
+	"currency":        "USD",
 }
 
 type structField struct {
 	name         string
+	title        string
 	desc         string
 	schemaType   string
 	arrayType    string
 	formatType   string
 	isRequired   bool
+	omitEmpty    bool
 	readOnly     bool
+	writeOnly    bool
 	crossPkg     string
 	exampleValue interface{}
 	maximum      *float64
 	minimum      *float64
 	maxLength    *int64
 	minLength    *int64
+	maxItems     *int64
+	minItems     *int64
+	uniqueItems  bool
+	itemFormat   string
 	enums        []interface{}
 	defaultValue interface{}
 	extensions   map[string]interface{}
 }
 
 func (parser *Parser) parseStructField(file *ast.File, field *ast.Field) (map[string]spec.Schema, []string, error) {
+	if isContextType(file, field.Type) {
+		// a context travels along with a value, it's never part of the payload
+		return nil, nil, nil
+	}
+
 	if field.Names == nil {
 		if field.Tag != nil {
 			skip, ok := reflect.StructTag(strings.ReplaceAll(field.Tag.Value, "`", "")).Lookup("swaggerignore")
@@ -897,6 +2360,17 @@ func (parser *Parser) parseStructField(file *ast.File, field *ast.Field) (map[st
 		}
 		schema, err := parser.getTypeSchema(typeName, file, false)
 		if err != nil {
+			if pkgPath, ok := stdLibPackagePath(file, typeName); ok {
+				// embedded standard types like sync.Mutex don't contribute to the payload
+				exported, importErr := parser.hasExportedFields(pkgPath, strings.Split(typeName, ".")[1])
+				if importErr != nil {
+					Printf("warning: skip embedded %s, failed to check its fields: %s", typeName, importErr)
+					return nil, nil, nil
+				}
+				if !exported {
+					return nil, nil, nil
+				}
+			}
 			return nil, nil, err
 		}
 		if len(schema.Type) > 0 && schema.Type[0] == OBJECT {
@@ -936,6 +2410,10 @@ func (parser *Parser) parseStructField(file *ast.File, field *ast.Field) (map[st
 	}
 
 	types := parser.GetSchemaTypePath(schema, 2)
+	if len(types) == 0 && reflect.DeepEqual(*schema, spec.Schema{}) {
+		// the schema of any json value, like the one of json.RawMessage, takes the tags of an object
+		types = []string{OBJECT}
+	}
 	if len(types) == 0 {
 		return nil, nil, fmt.Errorf("invalid type for field: %s", field.Names[0])
 	}
@@ -944,34 +2422,170 @@ func (parser *Parser) parseStructField(file *ast.File, field *ast.Field) (map[st
 	if err != nil {
 		return nil, nil, err
 	}
+	if !structField.readOnly && matchFieldPatterns(parser.ReadOnlyPatterns, field.Names[0].Name, fieldName) {
+		structField.readOnly = true
+	}
+	if structField.writeOnly || matchFieldPatterns(parser.WriteOnlyPatterns, field.Names[0].Name, fieldName) {
+		if structField.extensions == nil {
+			structField.extensions = map[string]interface{}{}
+		}
+		structField.extensions["x-writeOnly"] = true
+		// a string which is only sent, never returned, is a secret like a password
+		structField.setFormat("password")
+	}
 
 	if structField.schemaType == "string" && types[0] != structField.schemaType {
 		schema = PrimitiveSchema(structField.schemaType)
 	}
 
+	// the schema of a named type is shared by all its uses
+	fieldSchema := *schema
+	schema = &fieldSchema
+
+	schema.Title = structField.title
 	schema.Description = structField.desc
 	schema.ReadOnly = structField.readOnly
 	schema.Default = structField.defaultValue
 	schema.Example = structField.exampleValue
-	schema.Format = structField.formatType
-	schema.Extensions = structField.extensions
+	if structField.formatType != "" {
+		schema.Format = structField.formatType
+	}
+	if len(structField.extensions) > 0 {
+		extensions := spec.Extensions{}
+		for key, value := range schema.Extensions {
+			extensions[key] = value
+		}
+		for key, value := range structField.extensions {
+			extensions[key] = value
+		}
+		schema.Extensions = extensions
+	}
+	_, isPointer := field.Type.(*ast.StarExpr)
+	if isPointer {
+		// becomes nullable: true in OpenAPI 3.0
+		schema.AddExtension("x-nullable", true)
+	}
 	eleSchema := schema
 	if structField.schemaType == "array" {
-		eleSchema = schema.Items.Schema
+		itemSchema := *schema.Items.Schema
+		schema.Items = &spec.SchemaOrArray{Schema: &itemSchema}
+		eleSchema = &itemSchema
+
+		schema.MaxItems = structField.maxItems
+		schema.MinItems = structField.minItems
+		schema.UniqueItems = structField.uniqueItems
+		if structField.itemFormat != "" {
+			itemSchema.Format = structField.itemFormat
+		}
 	}
 	eleSchema.Maximum = structField.maximum
 	eleSchema.Minimum = structField.minimum
 	eleSchema.MaxLength = structField.maxLength
 	eleSchema.MinLength = structField.minLength
-	eleSchema.Enum = structField.enums
+	if structField.enums != nil {
+		eleSchema.Enum = structField.enums
+		if _, ok := eleSchema.Extensions["x-enum-varnames"]; ok {
+			// the names belong to the values of the constants
+			extensions := spec.Extensions{}
+			for key, value := range eleSchema.Extensions {
+				if key != "x-enum-varnames" {
+					extensions[key] = value
+				}
+			}
+			eleSchema.Extensions = extensions
+		}
+	}
+
+	if isPointer && schema.Ref.String() != "" {
+		// the siblings of $ref are ignored, so the nullable reference is wrapped
+		wrapped := *schema
+		wrapped.Ref = spec.Ref{}
+		wrapped.AllOf = []spec.Schema{{SchemaProps: spec.SchemaProps{Ref: schema.Ref}}}
+		schema = &wrapped
+	}
 
 	var tagRequired []string
-	if structField.isRequired {
+	// encoding/json may leave out an omitempty field, the tags of a nullable pointer override that
+	// unless NullableOmitEmpty is set
+	required := structField.isRequired && !structField.omitEmpty
+	if isPointer && structField.isRequired && structField.omitEmpty {
+		required = !parser.NullableOmitEmpty
+	}
+	if required {
 		tagRequired = append(tagRequired, fieldName)
 	}
 	return map[string]spec.Schema{fieldName: *schema}, tagRequired, nil
 }
 
+// isContextType reports whether expr refers to context.Context, honoring import aliases
+func isContextType(file *ast.File, expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Context" {
+		return false
+	}
+	pkgIdent, ok := selector.X.(*ast.Ident)
+	if !ok || file == nil {
+		return false
+	}
+
+	for _, imp := range file.Imports {
+		if strings.Trim(imp.Path.Value, `"`) != "context" {
+			continue
+		}
+		if imp.Name == nil && pkgIdent.Name == "context" || imp.Name != nil && imp.Name.Name == pkgIdent.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// stdLibPackagePath returns the path of the standard library package imported by file which typeName, like
+// sync.Mutex, belongs to.
+func stdLibPackagePath(file *ast.File, typeName string) (string, bool) {
+	if file == nil || !strings.ContainsRune(typeName, '.') {
+		return "", false
+	}
+	pkgName := strings.Split(typeName, ".")[0]
+
+	for _, imp := range file.Imports {
+		path := strings.Trim(imp.Path.Value, `"`)
+		if imp.Name != nil && imp.Name.Name != pkgName || imp.Name == nil && filepath.Base(path) != pkgName {
+			continue
+		}
+		return path, !strings.Contains(strings.Split(path, "/")[0], ".")
+	}
+	return "", false
+}
+
+// hasExportedFields reports whether the struct type typeName of the standard library package pkgPath has
+// exported fields, which are part of the payload. The package is type checked from the source of GOROOT.
+func (parser *Parser) hasExportedFields(pkgPath, typeName string) (bool, error) {
+	if parser.stdLibImporter == nil {
+		parser.stdLibImporter = importer.ForCompiler(token.NewFileSet(), "source", nil)
+	}
+	pkg, err := parser.stdLibImporter.Import(pkgPath)
+	if err != nil {
+		return false, err
+	}
+	typeObj := pkg.Scope().Lookup(typeName)
+	if typeObj == nil {
+		return false, fmt.Errorf("%s has no type %s", pkgPath, typeName)
+	}
+	structType, ok := typeObj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return false, nil
+	}
+	for i := 0; i < structType.NumFields(); i++ {
+		if structType.Field(i).Exported() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func getFieldType(field ast.Expr) (string, error) {
 	switch ftype := field.(type) {
 	case *ast.Ident:
@@ -990,12 +2604,29 @@ func getFieldType(field ast.Expr) (string, error) {
 		}
 		return fullName, nil
 	}
+	if _, _, ok := genericTypeArgs(field); ok {
+		return types.ExprString(field), nil
+	}
 	return "", fmt.Errorf("unknown field type %#v", field)
 }
 
+// matchFieldPatterns reports whether the go field name or the property name matches one of the patterns.
+func matchFieldPatterns(patterns []string, goName, propName string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, goName); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, propName); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (parser *Parser) getFieldName(field *ast.Field) (name string, schema *spec.Schema, err error) {
-	// Skip non-exported fields.
-	if !ast.IsExported(field.Names[0].Name) {
+	// Skip non-exported fields, unless they are tagged explicitly and ParseUnexportedFields is set.
+	exported := ast.IsExported(field.Names[0].Name)
+	if !exported && (!parser.ParseUnexportedFields || field.Tag == nil) {
 		return "", nil, nil
 	}
 
@@ -1006,13 +2637,20 @@ func (parser *Parser) getFieldName(field *ast.Field) (name string, schema *spec.
 			return "", nil, nil
 		}
 
-		name = structTag.Get("json")
+		// json:"-" skips the field, whereas json:"-," names it -
+		jsonTag := structTag.Get("json")
+		if strings.TrimSpace(jsonTag) == "-" {
+			return "", nil, nil
+		}
 		// json:"tag,hoge"
-		if name = strings.TrimSpace(strings.Split(name, ",")[0]); name == "-" {
+		name = strings.TrimSpace(strings.Split(jsonTag, ",")[0])
+
+		typeTag := structTag.Get("swaggertype")
+		if !exported && name == "" && typeTag == "" {
 			return "", nil, nil
 		}
 
-		if typeTag := structTag.Get("swaggertype"); typeTag != "" {
+		if typeTag != "" {
 			parts := strings.Split(typeTag, ",")
 			schema, err = BuildCustomSchema(parts)
 			if err != nil {
@@ -1060,19 +2698,36 @@ func (parser *Parser) parseFieldTag(field *ast.Field, types []string) (*structFi
 
 	jsonTag := structTag.Get("json")
 	// json:"name,string" or json:",string"
-	hasStringTag := strings.Contains(jsonTag, ",string")
+	hasStringTag, hasOmitEmpty := false, false
+	for _, option := range strings.Split(jsonTag, ",")[1:] {
+		switch strings.TrimSpace(option) {
+		case "string":
+			hasStringTag = true
+		case "omitempty":
+			hasOmitEmpty = true
+		}
+	}
 
 	if exampleTag := structTag.Get("example"); exampleTag != "" {
 		if hasStringTag {
 			// then the example must be in string format
 			structField.exampleValue = exampleTag
 		} else {
-			example, err := defineTypeOfExample(structField.schemaType, structField.arrayType, exampleTag)
+			example, err := defineTypeOfTagValue(structField.schemaType, structField.arrayType, exampleTag)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("invalid example %q of field %s: %s", exampleTag, fieldGoName(field), err)
 			}
 			structField.exampleValue = example
 		}
+	} else if parser.FakerTag != "" && structField.schemaType == STRING {
+		// faker:"email" or faker:"email,unique"
+		kind := strings.TrimSpace(strings.Split(structTag.Get(parser.FakerTag), ",")[0])
+		if example, ok := fakerExamples[strings.ToLower(kind)]; ok {
+			structField.exampleValue = example
+		}
+	}
+	if titleTag := structTag.Get("title"); titleTag != "" {
+		structField.title = titleTag
 	}
 	if formatTag := structTag.Get("format"); formatTag != "" {
 		structField.formatType = formatTag
@@ -1096,14 +2751,25 @@ func (parser *Parser) parseFieldTag(field *ast.Field, types []string) (*structFi
 	if extensionsTag := structTag.Get("extensions"); extensionsTag != "" {
 		structField.extensions = map[string]interface{}{}
 		for _, val := range strings.Split(extensionsTag, ",") {
+			val = strings.TrimSpace(val)
+			if val == "" {
+				continue
+			}
 			parts := strings.SplitN(val, "=", 2)
 			if len(parts) == 2 {
-				structField.extensions[parts[0]] = parts[1]
+				structField.extensions[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
 			} else {
 				structField.extensions[parts[0]] = true
 			}
 		}
 	}
+	structField.omitEmpty = hasOmitEmpty
+	if hasOmitEmpty && parser.OmitEmptyExtension {
+		if structField.extensions == nil {
+			structField.extensions = map[string]interface{}{}
+		}
+		structField.extensions["x-omitempty"] = true
+	}
 	if enumsTag := structTag.Get("enums"); enumsTag != "" {
 		enumType := structField.schemaType
 		if structField.schemaType == ARRAY {
@@ -1119,9 +2785,9 @@ func (parser *Parser) parseFieldTag(field *ast.Field, types []string) (*structFi
 		}
 	}
 	if defaultTag := structTag.Get("default"); defaultTag != "" {
-		value, err := defineType(structField.schemaType, defaultTag)
+		value, err := defineTypeOfTagValue(structField.schemaType, structField.arrayType, defaultTag)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid default %q of field %s: %s", defaultTag, fieldGoName(field), err)
 		}
 		structField.defaultValue = value
 	}
@@ -1155,6 +2821,14 @@ func (parser *Parser) parseFieldTag(field *ast.Field, types []string) (*structFi
 	if readOnly := structTag.Get("readonly"); readOnly != "" {
 		structField.readOnly = readOnly == "true"
 	}
+	if writeOnly := structTag.Get("writeonly"); writeOnly != "" {
+		structField.writeOnly = writeOnly == "true"
+	}
+	if parser.ValidateTagName != "" {
+		if validateTag := structTag.Get(parser.ValidateTagName); validateTag != "" {
+			applyValidateTag(structField, validateTag)
+		}
+	}
 
 	// perform this after setting everything else (min, max, etc...)
 	if hasStringTag {
@@ -1184,6 +2858,34 @@ func (parser *Parser) parseFieldTag(field *ast.Field, types []string) (*structFi
 }
 
 // GetSchemaTypePath get path of schema type
+// derefSchema returns the definition schema refers to, with the description, example and default of schema,
+// or schema itself if it isn't a reference.
+func (parser *Parser) derefSchema(schema spec.Schema) spec.Schema {
+	name := schema.Ref.String()
+	if len(schema.AllOf) == 1 && name == "" {
+		// a nullable reference
+		name = schema.AllOf[0].Ref.String()
+	}
+	pos := strings.LastIndexByte(name, '/')
+	if pos < 0 {
+		return schema
+	}
+	definition, ok := parser.swagger.Definitions[name[pos+1:]]
+	if !ok {
+		return schema
+	}
+	if schema.Description != "" {
+		definition.Description = schema.Description
+	}
+	if schema.Example != nil {
+		definition.Example = schema.Example
+	}
+	if schema.Default != nil {
+		definition.Default = schema.Default
+	}
+	return definition
+}
+
 func (parser *Parser) GetSchemaTypePath(schema *spec.Schema, depth int) []string {
 	if schema == nil || depth == 0 {
 		return nil
@@ -1213,9 +2915,140 @@ func (parser *Parser) GetSchemaTypePath(schema *spec.Schema, depth int) []string
 	return nil
 }
 
-func replaceLastTag(slice []spec.Tag, element spec.Tag) {
-	slice = slice[:len(slice)-1]
-	slice = append(slice, element)
+// applyValidateTag adds the keywords of go-playground/validator rules, like validate:"required,min=3,email",
+// which aren't set by other tags. The rules of an array, like validate:"min=1,unique,dive,max=10", apply to the
+// array itself up to dive and to its items after it. Unknown or malformed rules are ignored.
+func applyValidateTag(structField *structField, validateTag string) {
+	// schemaType the type of the schema the rules apply to, the one of the items after dive
+	schemaType, items := structField.schemaType, false
+	setFormat := func(format string) {
+		if items {
+			structField.setItemFormat(format)
+		} else {
+			structField.setFormat(format)
+		}
+	}
+	for _, rule := range strings.Split(validateTag, ",") {
+		name, param := strings.TrimSpace(rule), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, param = name[:i], name[i+1:]
+		}
+
+		switch name {
+		case "dive":
+			if schemaType != ARRAY || structField.arrayType == ARRAY || structField.arrayType == OBJECT {
+				// the items of maps and nested arrays have no keywords of their own here
+				return
+			}
+			schemaType, items = structField.arrayType, true
+		case "required":
+			if !items {
+				structField.isRequired = true
+			}
+		case "min", "gte":
+			structField.setLowerBound(schemaType, param)
+		case "max", "lte":
+			structField.setUpperBound(schemaType, param)
+		case "len":
+			structField.setLowerBound(schemaType, param)
+			structField.setUpperBound(schemaType, param)
+		case "unique":
+			structField.uniqueItems = schemaType == ARRAY
+		case "email":
+			setFormat("email")
+		case "uuid", "uuid3", "uuid4", "uuid5":
+			setFormat("uuid")
+		case "url", "uri":
+			setFormat("uri")
+		case "oneof":
+			if structField.enums != nil || schemaType == ARRAY || schemaType == OBJECT {
+				continue
+			}
+			var enums []interface{}
+			for _, value := range splitOneOfParam(param) {
+				enum, err := defineType(schemaType, value)
+				if err != nil {
+					enums = nil
+					break
+				}
+				enums = append(enums, enum)
+			}
+			structField.enums = enums
+		}
+	}
+}
+
+// splitOneOfParam splits the space separated values of a oneof rule, a value with spaces is single quoted.
+func splitOneOfParam(param string) []string {
+	var values []string
+	for param = strings.TrimSpace(param); param != ""; param = strings.TrimSpace(param) {
+		if param[0] == '\'' {
+			if end := strings.IndexByte(param[1:], '\''); end >= 0 {
+				values = append(values, param[1:end+1])
+				param = param[end+2:]
+				continue
+			}
+		}
+		end := strings.IndexByte(param, ' ')
+		if end < 0 {
+			end = len(param)
+		}
+		values = append(values, param[:end])
+		param = param[end:]
+	}
+	return values
+}
+
+// setLowerBound sets the minItems of an array, the minimum of a number or the minLength of a string,
+// unless it's set already. schemaType is the one of the field or, for the rules after dive, of its items.
+func (structField *structField) setLowerBound(schemaType, param string) {
+	switch {
+	case schemaType == ARRAY && structField.minItems == nil:
+		if value, err := strconv.ParseInt(param, 10, 64); err == nil {
+			structField.minItems = &value
+		}
+	case IsNumericType(schemaType) && structField.minimum == nil:
+		if value, err := strconv.ParseFloat(param, 64); err == nil {
+			structField.minimum = &value
+		}
+	case schemaType == STRING && structField.minLength == nil:
+		if value, err := strconv.ParseInt(param, 10, 64); err == nil {
+			structField.minLength = &value
+		}
+	}
+}
+
+// setUpperBound sets the maxItems of an array, the maximum of a number or the maxLength of a string,
+// unless it's set already. schemaType is the one of the field or, for the rules after dive, of its items.
+func (structField *structField) setUpperBound(schemaType, param string) {
+	switch {
+	case schemaType == ARRAY && structField.maxItems == nil:
+		if value, err := strconv.ParseInt(param, 10, 64); err == nil {
+			structField.maxItems = &value
+		}
+	case IsNumericType(schemaType) && structField.maximum == nil:
+		if value, err := strconv.ParseFloat(param, 64); err == nil {
+			structField.maximum = &value
+		}
+	case schemaType == STRING && structField.maxLength == nil:
+		if value, err := strconv.ParseInt(param, 10, 64); err == nil {
+			structField.maxLength = &value
+		}
+	}
+}
+
+// setFormat sets the format of a string, unless it's set already.
+func (structField *structField) setFormat(format string) {
+	if structField.schemaType == STRING && structField.formatType == "" {
+		structField.formatType = format
+	}
+}
+
+// setItemFormat sets the format of the string items of an array, unless it's set already.
+func (structField *structField) setItemFormat(format string) {
+	if structField.schemaType == ARRAY && structField.arrayType == STRING && structField.itemFormat == "" {
+		structField.itemFormat = format
+	}
 }
 
 func getFloatTag(structTag reflect.StructTag, tagName string) (*float64, error) {
@@ -1338,19 +3171,83 @@ func defineTypeOfExample(schemaType, arrayType, exampleValue string) (interface{
 	}
 }
 
+// defineTypeOfTagValue returns the value of an example or default struct tag typed by the schema type of the field.
+// The value of an array or object field can be written in json as well, like example:"[1,2,3]".
+func defineTypeOfTagValue(schemaType, arrayType, value string) (interface{}, error) {
+	jsonValue := strings.TrimSpace(value)
+	switch {
+	case schemaType == ARRAY && strings.HasPrefix(jsonValue, "["):
+		var items []interface{}
+		if err := json.Unmarshal([]byte(jsonValue), &items); err != nil {
+			return nil, fmt.Errorf("not a json array: %s", err)
+		}
+		for i, item := range items {
+			v, ok := jsonValueOfType(arrayType, item)
+			if !ok {
+				return nil, fmt.Errorf("item %v isn't a %s", item, arrayType)
+			}
+			items[i] = v
+		}
+		return items, nil
+	case schemaType == OBJECT && strings.HasPrefix(jsonValue, "{"):
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(jsonValue), &object); err != nil {
+			return nil, fmt.Errorf("not a json object: %s", err)
+		}
+		return object, nil
+	}
+	return defineTypeOfExample(schemaType, arrayType, value)
+}
+
+// jsonValueOfType reports whether the unmarshaled json value is of the schema type,
+// returning a whole number as an int for an integer.
+func jsonValueOfType(schemaType string, value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, schemaType == STRING
+	case bool:
+		return v, schemaType == BOOLEAN
+	case float64:
+		if schemaType == INTEGER && v == float64(int(v)) {
+			return int(v), true
+		}
+		return v, schemaType == NUMBER
+	case []interface{}:
+		return v, schemaType == ARRAY
+	case map[string]interface{}:
+		return v, schemaType == OBJECT
+	}
+	return value, false
+}
+
+// fieldGoName returns the name of a struct field as declared, or its type name if it's embedded.
+func fieldGoName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+	return types.ExprString(field.Type)
+}
+
 // GetAllGoFileInfo gets all Go source files information for given searchDir.
 func (parser *Parser) getAllGoFileInfo(packageDir, searchDir string) error {
 	return filepath.Walk(searchDir, func(path string, f os.FileInfo, err error) error {
 		if err := parser.Skip(path, f); err != nil {
 			return err
-		} else if f.IsDir() {
-			return nil
 		}
 
 		relPath, err := filepath.Rel(searchDir, path)
 		if err != nil {
 			return err
 		}
+		if parser.isExcluded(relPath) {
+			if f.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if f.IsDir() {
+			return nil
+		}
 
 		return parser.parseFile(filepath.ToSlash(filepath.Dir(filepath.Clean(filepath.Join(packageDir, relPath)))), path, nil)
 	})
@@ -1366,6 +3263,16 @@ func (parser *Parser) getAllGoFileInfoFromDeps(pkg *depth.Pkg) error {
 	if pkg.Raw == nil && pkg.Name == "C" {
 		return nil
 	}
+	// files of replaced modules are collected from their local directories already
+	if parser.isReplacedPackage(pkg.Name) {
+		for i := 0; i < len(pkg.Deps); i++ {
+			if err := parser.getAllGoFileInfoFromDeps(&pkg.Deps[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	srcDir := pkg.Raw.Dir
 	files, err := ioutil.ReadDir(srcDir) // only parsing files in the dir(don't contains sub dir files)
 	if err != nil {
@@ -1392,13 +3299,104 @@ func (parser *Parser) getAllGoFileInfoFromDeps(pkg *depth.Pkg) error {
 	return nil
 }
 
+// collectDependency collects the files of the dependency package the qualified typeName refers to from the
+// imports of file, if it's a package of the dependencyPrefixes which wasn't collected yet. It returns whether
+// any package was collected, only the package itself is, its own imports are once a type refers to them.
+func (parser *Parser) collectDependency(typeName string, file *ast.File) bool {
+	if len(parser.DependencyPrefixes) == 0 || file == nil || !strings.ContainsRune(typeName, '.') {
+		return false
+	}
+	info, ok := parser.packages.files[file]
+	if !ok {
+		return false
+	}
+	pkgName := strings.Split(typeName, ".")[0]
+
+	collected := false
+	for _, imp := range file.Imports {
+		pkgPath := strings.Trim(imp.Path.Value, `"`)
+		if imp.Name != nil && imp.Name.Name != pkgName || imp.Name == nil && importedPackageName(pkgPath) != pkgName {
+			continue
+		}
+		if parser.collectedDependencies[pkgPath] || !parser.isDependencyPackage(pkgPath) {
+			continue
+		}
+		if _, ok := parser.packages.packages[pkgPath]; ok {
+			continue
+		}
+		parser.collectedDependencies[pkgPath] = true
+
+		if err := parser.collectPackage(pkgPath, filepath.Dir(info.Path)); err != nil {
+			Printf("warning: failed to collect dependency package %s of type %s: %s", pkgPath, typeName, err)
+			continue
+		}
+		collected = true
+	}
+	return collected
+}
+
+// isDependencyPackage returns whether pkgPath is in a module of the dependencyPrefixes.
+func (parser *Parser) isDependencyPackage(pkgPath string) bool {
+	for _, prefix := range parser.DependencyPrefixes {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if pkgPath == prefix || strings.HasPrefix(pkgPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// collectPackage collects the Go files of the package pkgPath, as imported by the files of srcDir, and gathers their types.
+func (parser *Parser) collectPackage(pkgPath, srcDir string) error {
+	dir, err := parser.findPackageDir(pkgPath, srcDir)
+	if err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := parser.parseFile(pkgPath, filepath.Join(dir, f.Name()), nil); err != nil {
+			return err
+		}
+	}
+
+	parsedSchemas, err := parser.packages.ParseTypes()
+	if err != nil {
+		return err
+	}
+	for typeSpecDef, schema := range parsedSchemas {
+		parser.parsedSchemas[typeSpecDef] = schema
+	}
+	return nil
+}
+
+// importedPackageName returns the name a package is usually declared with by its import path, the last element
+// without a go- prefix or a suffix like .go or .v2, skipping a major version element like v2.
+func importedPackageName(pkgPath string) string {
+	elems := strings.Split(pkgPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
 func (parser *Parser) parseFile(packageDir, path string, src interface{}) error {
 	if strings.HasSuffix(strings.ToLower(path), "_test.go") || filepath.Ext(path) != ".go" {
 		return nil
 	}
 
 	// positions are relative to FileSet
-	astFile, err := goparser.ParseFile(token.NewFileSet(), path, src, goparser.ParseComments)
+	astFile, err := goparser.ParseFile(parser.fileSet, path, src, goparser.ParseComments)
 	if err != nil {
 		return fmt.Errorf("ParseFile error:%+v", err)
 	}
@@ -1421,34 +3419,22 @@ func (parser *Parser) checkOperationIDUniqueness() error {
 		operationsIds[operationID] = currentPath
 		return nil
 	}
-	getOperationID := func(itm spec.PathItem) (string, string) {
-		if itm.Get != nil {
-			return "GET", itm.Get.ID
-		}
-		if itm.Put != nil {
-			return "PUT", itm.Put.ID
-		}
-		if itm.Post != nil {
-			return "POST", itm.Post.ID
-		}
-		if itm.Delete != nil {
-			return "DELETE", itm.Delete.ID
-		}
-		if itm.Options != nil {
-			return "OPTIONS", itm.Options.ID
-		}
-		if itm.Head != nil {
-			return "HEAD", itm.Head.ID
-		}
-		if itm.Patch != nil {
-			return "PATCH", itm.Patch.ID
-		}
-		return "", ""
-	}
 	for path, itm := range parser.swagger.Paths.Paths {
-		method, id := getOperationID(itm)
-		if err := saveOperationID(id, fmt.Sprintf("%s %s", method, path)); err != nil {
-			return err
+		for method, op := range map[string]*spec.Operation{
+			http.MethodGet:     itm.Get,
+			http.MethodPut:     itm.Put,
+			http.MethodPost:    itm.Post,
+			http.MethodDelete:  itm.Delete,
+			http.MethodOptions: itm.Options,
+			http.MethodHead:    itm.Head,
+			http.MethodPatch:   itm.Patch,
+		} {
+			if op == nil {
+				continue
+			}
+			if err := saveOperationID(op.ID, fmt.Sprintf("%s %s", method, path)); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -1473,12 +3459,93 @@ func (parser *Parser) Skip(path string, f os.FileInfo) error {
 	return nil
 }
 
+// isExcluded whether relPath, relative to the search dir, matches a path prefix or glob pattern of the excludes.
+// Paths are matched case-insensitively on Windows.
+func (parser *Parser) isExcluded(relPath string) bool {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	if relPath == "." {
+		return false
+	}
+	for pattern := range parser.excludes {
+		pattern = filepath.ToSlash(pattern)
+		target := relPath
+		if runtime.GOOS == "windows" {
+			pattern, target = strings.ToLower(pattern), strings.ToLower(target)
+		}
+		if target == pattern || strings.HasPrefix(target, pattern+"/") {
+			return true
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTypeOverride registers the schema of the type with the given full name, e.g. "uuid.UUID", which wins over
+// resolving the type from source, even over copies of the type in the parsed source like vendored ones.
+// A full name with the import path, e.g. "github.com/google/uuid.UUID", applies to that package only instead
+// of any package of the name. Objects become definitions which are referenced, other schemas are used inline.
+// The standard library types without parsable source, like time.Time, and the types of TreatAsString are
+// registered the same way.
+func (parser *Parser) AddTypeOverride(fullName string, schema *spec.Schema) {
+	pos := strings.LastIndexByte(fullName, '.')
+	if pos < 0 || !strings.ContainsRune(fullName[:pos], '/') {
+		parser.addTypeOverride("", fullName, schema)
+		return
+	}
+	parser.addTypeOverride(fullName[:pos], fullName[pos+1:], schema)
+}
+
+// registerTypeOptions registers the type overrides of the options, like TreatAsString, once the first type is
+// resolved, so the options may be set after New.
+func (parser *Parser) registerTypeOptions() {
+	if parser.typeOptionsRegistered {
+		return
+	}
+	parser.typeOptionsRegistered = true
+
+	if parser.JSONNumberAsString {
+		parser.addTypeOverride("encoding/json", "Number", PrimitiveSchema(STRING))
+	}
+	for _, typeName := range parser.TreatAsString {
+		parser.AddTypeOverride(typeName, PrimitiveSchema(STRING))
+	}
+}
+
+// addTypeOverride registers the schema of the type typeName of the package pkgPath, or, if pkgPath is empty,
+// of the type with the full name typeName of any package.
+func (parser *Parser) addTypeOverride(pkgPath, typeName string, schema *spec.Schema) {
+	if parser.packages.typeOverrides == nil {
+		parser.packages.typeOverrides = make(map[string]*TypeSpecDef)
+	}
+	if pkgPath != "" {
+		typeName = fullTypeName(assumedPackageName(pkgPath), typeName)
+	}
+	typeSpecDef := &TypeSpecDef{PkgPath: pkgPath}
+	parser.packages.typeOverrides[typeName] = typeSpecDef
+	parser.parsedSchemas[typeSpecDef] = &Schema{
+		Name:   typeName,
+		Schema: schema,
+	}
+}
+
 // GetSwagger returns *spec.Swagger which is the root document object for the API specification.
 func (parser *Parser) GetSwagger() *spec.Swagger {
 	return parser.swagger
 }
 
-//addTestType just for tests
+// SourceFiles returns the paths of the Go files the swagger doc was parsed from, sorted.
+func (parser *Parser) SourceFiles() []string {
+	files := parser.packages.sortedFiles()
+	paths := make([]string, 0, len(files))
+	for _, info := range files {
+		paths = append(paths, info.Path)
+	}
+	return paths
+}
+
+// addTestType just for tests
 func (parser *Parser) addTestType(typename string) {
 	if parser.parsedSchemas == nil {
 		parser.parsedSchemas = make(map[*TypeSpecDef]*Schema)
@@ -2,18 +2,38 @@ package swag
 
 import (
 	"encoding/json"
+	"fmt"
+	"go/ast"
 	goparser "go/parser"
 	"go/token"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/go-openapi/spec"
 	"github.com/stretchr/testify/assert"
 )
 
 const defaultParseDepth = 100
 
+// parseTestSource parses the types and the operations of src, the source of the api package, with p.
+func parseTestSource(t *testing.T, p *Parser, src string) *ast.File {
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+	return f
+}
+
 func TestNew(t *testing.T) {
 	swagMode = test
 	New()
@@ -532,7 +552,8 @@ func TestParseSimpleApi_ForSnakecase(t *testing.T) {
             "type": "object",
             "properties": {
                 "created_at": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "error_code": {
                     "type": "integer"
@@ -642,7 +663,8 @@ func TestParseSimpleApi_ForSnakecase(t *testing.T) {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/web.Pet2"
-                    }
+                    },
+                    "x-nullable": true
                 },
                 "pets2": {
                     "type": "array",
@@ -662,6 +684,8 @@ func TestParseSimpleApi_ForSnakecase(t *testing.T) {
                 },
                 "price": {
                     "type": "number",
+                    "maximum": 130,
+                    "minimum": 0,
                     "example": 3.25
                 },
                 "status": {
@@ -682,13 +706,16 @@ func TestParseSimpleApi_ForSnakecase(t *testing.T) {
             "type": "object",
             "properties": {
                 "deleted_at": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
                 },
                 "id": {
                     "type": "integer"
                 },
                 "middle_name": {
-                    "type": "string"
+                    "type": "string",
+                    "x-nullable": true
                 }
             }
         },
@@ -1017,7 +1044,8 @@ func TestParseSimpleApi_ForLowerCamelcase(t *testing.T) {
             "type": "object",
             "properties": {
                 "createdAt": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "errorCode": {
                     "type": "integer"
@@ -1100,7 +1128,8 @@ func TestParseSimpleApi_ForLowerCamelcase(t *testing.T) {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/web.Pet2"
-                    }
+                    },
+                    "x-nullable": true
                 },
                 "pets2": {
                     "type": "array",
@@ -1140,13 +1169,16 @@ func TestParseSimpleApi_ForLowerCamelcase(t *testing.T) {
             "type": "object",
             "properties": {
                 "deletedAt": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
                 },
                 "id": {
                     "type": "integer"
                 },
                 "middleName": {
-                    "type": "string"
+                    "type": "string",
+                    "x-nullable": true
                 }
             }
         },
@@ -1304,7 +1336,8 @@ func TestParseStructComment(t *testing.T) {
             "properties": {
                 "createdAt": {
                     "description": "Error time",
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "error": {
                     "description": "Error an Api error",
@@ -1486,13 +1519,15 @@ func TestParseModelAsTypeAlias(t *testing.T) {
             "type": "object",
             "properties": {
                 "created_at": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "name": {
                     "type": "string"
                 },
                 "timestamp": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time"
                 }
             }
         }
@@ -1754,11 +1789,17 @@ func Test(){
       "properties": {
          "test1": {
             "description": "test1",
-            "type": "string"
+            "type": "string",
+            "x-nullable": true
          },
          "test2": {
             "description": "test2",
-            "$ref": "#/definitions/api.Child"
+            "allOf": [
+               {
+                  "$ref": "#/definitions/api.Child"
+               }
+            ],
+            "x-nullable": true
          }
       }
    }
@@ -2116,6 +2157,48 @@ func Test3(){
 	assert.NotNil(t, val.Delete)
 }
 
+func TestParser_ParseRouterPrefixTrim(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package test
+
+// @Router /api/users/{id} [get]
+func Test1(){
+}
+
+// @Router /api [get]
+// @Router /api/ [post]
+func Test2(){
+}
+
+// @Router /apis/{id} [get]
+func Test3(){
+}
+
+// @Router /health [get]
+func Test4(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.RouterPrefixTrim = "/api/"
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	var paths []string
+	for path := range p.swagger.Paths.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	assert.Equal(t, []string{"/", "/apis/{id}", "/health", "/users/{id}"}, paths)
+	root := p.swagger.Paths.Paths["/"]
+	assert.NotNil(t, root.Get)
+	assert.NotNil(t, root.Post)
+}
+
 // func TestParseDeterministic(t *testing.T) {
 // 	mainAPIFile := "main.go"
 // 	for _, searchDir := range []string{
@@ -2555,3 +2638,3512 @@ func TestDefineTypeOfExample(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, example)
 }
+
+func TestParser_ParseStructFuncAndChanMembers(t *testing.T) {
+	src := `
+package api
+
+type Response struct {
+	Name     string
+	Callback func(string) error
+	Events   chan string
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Response": {
+      "type": "object",
+      "properties": {
+         "name": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	f := parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	p = New()
+	p.StrictFieldTypes = true
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	err = p.ParseRouterAPIInfo("", f)
+	assert.Error(t, err)
+}
+
+func TestParser_ParseInt64AsString(t *testing.T) {
+	src := `
+package api
+
+type Response struct {
+	ID    int64
+	Count int
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	id := p.swagger.Definitions["api.Response"].Properties["id"]
+	assert.Equal(t, INTEGER, id.Type[0])
+	assert.Empty(t, id.Format)
+
+	p = New()
+	p.Int64AsString = true
+	parseTestSource(t, p, src)
+
+	id = p.swagger.Definitions["api.Response"].Properties["id"]
+	assert.Equal(t, STRING, id.Type[0])
+	assert.Equal(t, "int64", id.Format)
+	count := p.swagger.Definitions["api.Response"].Properties["count"]
+	assert.Equal(t, INTEGER, count.Type[0])
+}
+
+func TestParser_ParseJSONNumber(t *testing.T) {
+	src := `
+package api
+
+import "encoding/json"
+
+type Measurement struct {
+	Value  json.Number
+	Values []json.Number
+}
+
+// @Success 200 {object} Measurement
+// @Router /measurements/{id} [get]
+func Test(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	value := p.swagger.Definitions["api.Measurement"].Properties["value"]
+	assert.Equal(t, spec.StringOrArray{NUMBER}, value.Type)
+	values := p.swagger.Definitions["api.Measurement"].Properties["values"]
+	assert.Equal(t, spec.StringOrArray{NUMBER}, values.Items.Schema.Type)
+	assert.NotContains(t, p.swagger.Definitions, "json.Number")
+
+	p = New()
+	p.JSONNumberAsString = true
+	parseTestSource(t, p, src)
+
+	value = p.swagger.Definitions["api.Measurement"].Properties["value"]
+	assert.Equal(t, spec.StringOrArray{STRING}, value.Type)
+}
+
+func TestParser_ParseRouterFirst(t *testing.T) {
+	src := `
+package test
+
+// @Router /api/{id} [post]
+// @Header 200 {string} Token "qwerty"
+// @Summary Create the thing
+// @Param id path int true "ID"
+// @Success 200 {string} string "ok"
+// @Tags things
+func Test(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	ps := p.swagger.Paths.Paths
+	val, ok := ps["/api/{id}"]
+	assert.True(t, ok)
+	assert.NotNil(t, val.Post)
+	assert.Equal(t, "Create the thing", val.Post.Summary)
+	assert.Equal(t, []string{"things"}, val.Post.Tags)
+	assert.Len(t, val.Post.Parameters, 1)
+	assert.Equal(t, "ok", val.Post.Responses.StatusCodeResponses[200].Description)
+	assert.Equal(t, "qwerty", val.Post.Responses.StatusCodeResponses[200].Headers["Token"].Description)
+}
+
+func TestParser_ParseStructFieldExtensions(t *testing.T) {
+	src := `
+package api
+
+type Response struct {
+	Name string ` + "`" + `json:"name" extensions:"x-foo=bar, x-nullable"` + "`" + `
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Response": {
+      "type": "object",
+      "properties": {
+         "name": {
+            "type": "string",
+            "x-foo": "bar",
+            "x-nullable": true
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParseOnlyLocalRefs(t *testing.T) {
+	var collectRefs func(v interface{}, refs []string) []string
+	collectRefs = func(v interface{}, refs []string) []string {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			for key, item := range value {
+				if ref, ok := item.(string); ok && key == "$ref" {
+					refs = append(refs, ref)
+					continue
+				}
+				refs = collectRefs(item, refs)
+			}
+		case []interface{}:
+			for _, item := range value {
+				refs = collectRefs(item, refs)
+			}
+		}
+		return refs
+	}
+
+	for _, searchDir := range []string{"testdata/composition", "testdata/alias_import"} {
+		p := New()
+		err := p.ParseAPI(searchDir, "main.go", defaultParseDepth)
+		assert.NoError(t, err)
+
+		b, err := json.Marshal(p.swagger)
+		assert.NoError(t, err)
+
+		var doc interface{}
+		assert.NoError(t, json.Unmarshal(b, &doc))
+
+		refs := collectRefs(doc, nil)
+		assert.NotEmpty(t, refs)
+		for _, ref := range refs {
+			assert.True(t, strings.HasPrefix(ref, "#/definitions/"), ref)
+			_, ok := p.swagger.Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+			assert.True(t, ok, ref)
+		}
+	}
+}
+
+func TestParser_ParseStructFieldJSONStringOption(t *testing.T) {
+	src := `
+package api
+
+type Response struct {
+	Amount int ` + "`" + `json:"amount,string"` + "`" + `
+	Count  int ` + "`" + `json:"count,omitempty"` + "`" + `
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Response": {
+      "type": "object",
+      "properties": {
+         "amount": {
+            "type": "string",
+            "example": "0"
+         },
+         "count": {
+            "type": "integer"
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_AddTypeOverrideObject(t *testing.T) {
+	src := `
+package api
+
+type Order struct {
+	Price money.Money
+}
+
+// @Success 200 {object} Order
+// @Failure 400 {object} money.Money
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+
+	p := New()
+	p.AddTypeOverride("money.Money", &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{OBJECT},
+			Properties: map[string]spec.Schema{
+				"amount":   *PrimitiveSchema(STRING),
+				"currency": *PrimitiveSchema(STRING),
+			},
+		},
+	})
+	parseTestSource(t, p, src)
+
+	money, ok := p.swagger.Definitions["money.Money"]
+	assert.True(t, ok)
+	assert.Len(t, money.Properties, 2)
+	price := p.swagger.Definitions["api.Order"].Properties["price"]
+	assert.Equal(t, "#/definitions/money.Money", price.Ref.String())
+	ref := p.swagger.Paths.Paths["/api/{id}"].Get.Responses.StatusCodeResponses[400].Schema.Ref
+	assert.Equal(t, "#/definitions/money.Money", ref.String())
+}
+
+func TestParser_ParseStructContextMember(t *testing.T) {
+	src := `
+package api
+
+import (
+	"context"
+	stdctx "context"
+)
+
+type Request struct {
+	context.Context
+	Ctx    stdctx.Context
+	Name   string
+}
+
+// @Param request body Request true "request"
+// @Success 200
+// @Router /api/{id} [post]
+func Test(){
+}
+`
+	expected := `{
+   "api.Request": {
+      "type": "object",
+      "properties": {
+         "name": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseParamDefaultFromConst(t *testing.T) {
+	src := `
+package api
+
+const (
+	DefaultLimit = 20
+	DefaultOrder = "desc"
+	MaxLimit = 10 * 10 + (1 << 3)
+)
+
+// @Param limit query int false "limit" default(@DefaultLimit)
+// @Param order query string false "order" default(@DefaultOrder)
+// @Param max query int false "max" default(@MaxLimit)
+// @Success 200
+// @Router /api [get]
+func Test(){
+}
+`
+	p := New()
+	f := parseTestSource(t, p, src)
+
+	params := p.swagger.Paths.Paths["/api"].Get.Parameters
+	assert.Len(t, params, 3)
+	assert.Equal(t, 20, params[0].Default)
+	assert.Equal(t, "desc", params[1].Default)
+	assert.Equal(t, 108, params[2].Default)
+
+	src = `
+package api
+
+// @Param limit query int false "limit" default(@Missing)
+// @Router /api [get]
+func Test(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+	assert.Error(t, New().ParseRouterAPIInfo("", f))
+
+	src = `
+package api
+
+const DefaultOrder = "desc"
+
+// @Param limit query int false "limit" default(@DefaultOrder)
+// @Router /api [get]
+func Test(){
+}
+`
+	f, err = goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+	p = New()
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	err = p.ParseRouterAPIInfo("", f)
+	assert.EqualError(t, err, `ParseComment error in file  :const DefaultOrder of default doesn't match type integer: desc. `+
+		`comment=limit query int false "limit" default(@DefaultOrder)`)
+}
+
+func TestParser_ParseOmitEmptyExtension(t *testing.T) {
+	src := `
+package api
+
+type Response struct {
+	Name  string ` + "`" + `json:"name,omitempty"` + "`" + `
+	Count int    ` + "`" + `json:"count"` + "`" + `
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Response": {
+      "type": "object",
+      "properties": {
+         "count": {
+            "type": "integer"
+         },
+         "name": {
+            "type": "string",
+            "x-omitempty": true
+         }
+      }
+   }
+}`
+
+	p := New()
+	p.OmitEmptyExtension = true
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	p = New()
+	parseTestSource(t, p, src)
+	assert.Empty(t, p.swagger.Definitions["api.Response"].Properties["name"].Extensions)
+}
+
+func TestParser_ParseCommentSource(t *testing.T) {
+	src := `
+package api
+
+// @Summary get user
+// @ID get-user
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`
+	tmpl := `{{/* @ID get-user */}}
+{{/* @Description Renders the user page */}}
+{{/* @Produce html */}}
+<div>{{ .Name }}</div>
+
+# @Summary list users
+# @ID list-users
+# @Success 200 {array} string
+# @Router /users [get]
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	err = p.ParseCommentSource("users.tmpl", []byte(tmpl))
+	assert.NoError(t, err)
+
+	getUser := p.swagger.Paths.Paths["/users/{id}"].Get
+	assert.Equal(t, "get user", getUser.Summary)
+	assert.Equal(t, "Renders the user page", getUser.Description)
+	assert.Equal(t, []string{"text/html"}, getUser.Produces)
+
+	listUsers := p.swagger.Paths.Paths["/users"].Get
+	if assert.NotNil(t, listUsers) {
+		assert.Equal(t, "list-users", listUsers.ID)
+		assert.Equal(t, "list users", listUsers.Summary)
+		assert.Equal(t, "array", listUsers.Responses.StatusCodeResponses[200].Schema.Type[0])
+	}
+
+	err = p.ParseCommentSource("users.tmpl", []byte("// @ID unknown\n// @Summary unknown"))
+	assert.Error(t, err)
+}
+
+func TestParser_ParseNamedSliceType(t *testing.T) {
+	src := `
+package api
+
+type IDs []int64
+
+type Codes [4]string
+
+type Request struct {
+	IDs   IDs   ` + "`" + `json:"ids"` + "`" + `
+	Codes Codes ` + "`" + `json:"codes"` + "`" + `
+}
+
+// @Param request body Request true "request"
+// @Success 200 {object} IDs
+// @Router /api [post]
+func Test(){
+}
+`
+	expected := `{
+   "api.Request": {
+      "type": "object",
+      "properties": {
+         "codes": {
+            "type": "array",
+            "items": {
+               "type": "string"
+            }
+         },
+         "ids": {
+            "type": "array",
+            "items": {
+               "type": "integer"
+            }
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	schema := p.swagger.Paths.Paths["/api"].Post.Responses.StatusCodeResponses[200].Schema
+	assert.Equal(t, "array", schema.Type[0])
+	assert.Equal(t, "integer", schema.Items.Schema.Type[0])
+}
+
+func TestParser_ParseNamedMapType(t *testing.T) {
+	src := `
+package api
+
+type Headers map[string]string
+
+type Request struct {
+	Headers Headers ` + "`" + `json:"headers"` + "`" + `
+}
+
+// @Param request body Request true "request"
+// @Router /api [post]
+func Test(){
+}
+`
+	expected := `{
+   "api.Headers": {
+      "type": "object",
+      "additionalProperties": {
+         "type": "string"
+      }
+   },
+   "api.Request": {
+      "type": "object",
+      "properties": {
+         "headers": {
+            "$ref": "#/definitions/api.Headers"
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseOperationOrder(t *testing.T) {
+	src := `
+package api
+
+// @Summary list users
+// @x-order 20
+// @Router /users [get]
+func ListUsers(){
+}
+
+// @Summary create user
+// @x-order 10
+// @Router /users [post]
+func CreateUser(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	pathItem := p.swagger.Paths.Paths["/users"]
+	assert.Equal(t, float64(20), pathItem.Get.Extensions["x-order"])
+	assert.Equal(t, float64(10), pathItem.Post.Extensions["x-order"])
+
+	b, _ := json.Marshal(pathItem.Post)
+	assert.Contains(t, string(b), `"x-order":10`)
+}
+
+func TestParser_ParseFakerTagExample(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	Email string ` + "`" + `json:"email" faker:"email"` + "`" + `
+	Name  string ` + "`" + `json:"name" faker:"name" example:"Jane"` + "`" + `
+	Age   int    ` + "`" + `json:"age" faker:"email"` + "`" + `
+}
+
+// @Success 200 {object} User
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.User": {
+      "type": "object",
+      "properties": {
+         "age": {
+            "type": "integer"
+         },
+         "email": {
+            "type": "string",
+            "example": "john.doe@example.com"
+         },
+         "name": {
+            "type": "string",
+            "example": "Jane"
+         }
+      }
+   }
+}`
+
+	p := New()
+	p.FakerTag = "faker"
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseUnexportedFields(t *testing.T) {
+	src := `
+package api
+
+type Response struct {
+	Name     string
+	internal string ` + "`" + `json:"internal"` + "`" + `
+	typed    int    ` + "`" + `swaggertype:"string"` + "`" + `
+	skipped  string ` + "`" + `json:"-"` + "`" + `
+	untagged string
+	other    string ` + "`" + `binding:"required"` + "`" + `
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Response": {
+      "type": "object",
+      "properties": {
+         "internal": {
+            "type": "string"
+         },
+         "name": {
+            "type": "string"
+         },
+         "typed": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	p.ParseUnexportedFields = true
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	p = New()
+	parseTestSource(t, p, src)
+	assert.Len(t, p.swagger.Definitions["api.Response"].Properties, 1)
+}
+
+func TestParser_ParseGeneralAPIInfoFromPackageDoc(t *testing.T) {
+	src := `
+// Package api is the users API.
+//
+// @title Users API
+// @version 1.2
+// @BasePath /v1
+// @securityDefinitions.basic BasicAuth
+package api
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "doc.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("api", "api/doc.go", f)
+	err = p.parseGeneralAPIInfoFromPackageDocs(".", "main.go")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Users API", p.swagger.Info.Title)
+	assert.Equal(t, "1.2", p.swagger.Info.Version)
+	assert.Equal(t, "/v1", p.swagger.BasePath)
+	assert.Contains(t, p.swagger.SecurityDefinitions, "BasicAuth")
+
+	src = `
+// Package api serves users.
+package api
+
+// @Summary get user
+// @Router /users [get]
+func GetUser() {}
+`
+	f, err = goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p = New()
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	err = p.parseGeneralAPIInfoFromPackageDocs(".", "main.go")
+	assert.NoError(t, err)
+	assert.Empty(t, p.swagger.Info.Title)
+}
+
+func TestParser_ParseGeneralAPIInfoFromPackageDocsPrecedence(t *testing.T) {
+	parseDoc := func(src string) *ast.File {
+		f, err := goparser.ParseFile(token.NewFileSet(), "doc.go", src, goparser.ParseComments)
+		assert.NoError(t, err)
+		return f
+	}
+	mainFile := parseDoc(`
+// @title Main API
+// @tag.name users
+package main
+`)
+	usersDoc := parseDoc(`
+// @title Users API
+// @version 1.2
+// @tag.name users
+// @tag.description Users of the shop
+package users
+`)
+	petsDoc := parseDoc(`
+// @version 1.2
+// @tag.name pets
+package pets
+`)
+
+	p := New()
+	p.packages.CollectAstFile("users", "api/users/doc.go", usersDoc)
+	p.packages.CollectAstFile("pets", "api/pets/doc.go", petsDoc)
+	// neither vendored nor dependency packages belong to the API
+	p.packages.CollectAstFile("vendored", "api/vendor/vendored/doc.go", parseDoc(`
+// @title Vendored API
+// @host vendored.example.com
+package vendored
+`))
+	p.packages.CollectAstFile("dependency", "../dependency/doc.go", parseDoc(`
+// @title Dependency API
+// @host dependency.example.com
+package dependency
+`))
+	assert.NoError(t, p.parseGeneralAPIInfoFromPackageDocs("api", "main.go"))
+	assert.Equal(t, "Users API", p.swagger.Info.Title)
+	assert.Equal(t, "Users of the shop", p.swagger.Tags[1].Description)
+	assert.NoError(t, p.parseGeneralAPIComment(mainFile.Doc))
+
+	// the main file wins
+	assert.Equal(t, "Main API", p.swagger.Info.Title)
+	assert.Equal(t, "1.2", p.swagger.Info.Version)
+	assert.Empty(t, p.swagger.Host)
+	assert.Len(t, p.swagger.Tags, 2)
+	assert.Equal(t, "pets", p.swagger.Tags[0].Name)
+	assert.Equal(t, "users", p.swagger.Tags[1].Name)
+	assert.Empty(t, p.swagger.Tags[1].Description)
+
+	// package docs must not contradict each other
+	p = New()
+	p.packages.CollectAstFile("users", "api/users/doc.go", usersDoc)
+	p.packages.CollectAstFile("pets", "api/pets/doc.go", parseDoc(`
+// @version 2.0
+package pets
+`))
+	err := p.parseGeneralAPIInfoFromPackageDocs("api", "main.go")
+	assert.EqualError(t, err, "cannot parse general api info in file api/users/doc.go: conflicting values of info.version: 2.0 and 1.2")
+
+	// so do their tags
+	p = New()
+	p.packages.CollectAstFile("users", "api/users/doc.go", usersDoc)
+	p.packages.CollectAstFile("pets", "api/pets/doc.go", parseDoc(`
+// @tag.name users
+// @tag.description Users of the pet shop
+package pets
+`))
+	err = p.parseGeneralAPIInfoFromPackageDocs("api", "main.go")
+	assert.Error(t, err)
+}
+
+func TestParser_ParseGeneralAPIExternalDocs(t *testing.T) {
+	src := `
+// @title Users API
+// @version 1.2
+// @externalDocs.description OpenAPI
+// @externalDocs.url https://swagger.io/resources/open-api/
+package api
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "doc.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("api", "api/doc.go", f)
+	err = p.parseGeneralAPIInfoFromPackageDocs(".", "main.go")
+	assert.NoError(t, err)
+
+	b, _ := json.Marshal(p.swagger.ExternalDocs)
+	assert.Equal(t, `{"description":"OpenAPI","url":"https://swagger.io/resources/open-api/"}`, string(b))
+}
+
+func TestParser_ParseTreatAsString(t *testing.T) {
+	src := `
+package api
+
+import "time"
+
+type Status struct {
+	code int
+}
+
+func (s Status) String() string {
+	return ""
+}
+
+type Response struct {
+	Status   Status
+	Statuses []Status
+	Timeout  time.Duration
+}
+
+// @Success 200 {object} Response
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Response": {
+      "type": "object",
+      "properties": {
+         "status": {
+            "type": "string"
+         },
+         "statuses": {
+            "type": "array",
+            "items": {
+               "type": "string"
+            }
+         },
+         "timeout": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	p.TreatAsString = []string{"api.Status", "time.Duration"}
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseNetIPAndURLFields(t *testing.T) {
+	src := `
+package api
+
+import (
+	"net"
+	"net/url"
+	nurl "net/url"
+)
+
+type Server struct {
+	Address   net.IP
+	AddressV6 net.IP ` + "`" + `format:"ipv6"` + "`" + `
+	Homepage  *url.URL
+	Mirrors   []url.URL
+	Docs      nurl.URL
+}
+
+// @Success 200 {object} Server
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	// a package of another path, which happens to be named url as well, is parsed from source
+	links := `
+package api
+
+import "example.com/shop/url"
+
+type Link struct {
+	Target url.URL
+}
+
+// @Success 200 {object} Link
+// @Router /api/link [get]
+func GetLink(){
+}
+`
+	shopURL := `
+package url
+
+type URL struct {
+	Slug string
+}
+`
+	expected := `{
+   "api.Link": {
+      "type": "object",
+      "properties": {
+         "target": {
+            "$ref": "#/definitions/url.URL"
+         }
+      }
+   },
+   "api.Server": {
+      "type": "object",
+      "properties": {
+         "address": {
+            "type": "string"
+         },
+         "addressV6": {
+            "type": "string",
+            "format": "ipv6"
+         },
+         "docs": {
+            "type": "string",
+            "format": "uri"
+         },
+         "homepage": {
+            "type": "string",
+            "format": "uri",
+            "x-nullable": true
+         },
+         "mirrors": {
+            "type": "array",
+            "items": {
+               "type": "string",
+               "format": "uri"
+            }
+         }
+      }
+   },
+   "url.URL": {
+      "type": "object",
+      "properties": {
+         "slug": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	for fileName, fileSrc := range map[string]string{"api/api.go": src, "api/links.go": links, "url/url.go": shopURL} {
+		f, err := goparser.ParseFile(token.NewFileSet(), "", fileSrc, goparser.ParseComments)
+		assert.NoError(t, err)
+		pkgPath := "api"
+		if fileName == "url/url.go" {
+			pkgPath = "example.com/shop/url"
+		}
+		p.packages.CollectAstFile(pkgPath, fileName, f)
+	}
+	_, err := p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	err = p.packages.RangeFiles(p.ParseRouterAPIInfo)
+	assert.NoError(t, err)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParsePackageProduce(t *testing.T) {
+	doc := `
+// Package api serves users.
+//
+// @Produce json,xml
+package api
+`
+	src := `
+package api
+
+// @Summary list users
+// @Router /users [get]
+func ListUsers(){
+}
+
+// @Summary delete user
+// @Router /users [delete]
+func DeleteUser(){
+}
+
+// @Summary user page
+// @Produce html
+// @Router /users [post]
+func UserPage(){
+}
+`
+	docFile, err := goparser.ParseFile(token.NewFileSet(), "doc.go", doc, goparser.ParseComments)
+	assert.NoError(t, err)
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("api", "api/doc.go", docFile)
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	pathItem := p.swagger.Paths.Paths["/users"]
+	assert.Equal(t, []string{"application/json", "text/xml"}, pathItem.Get.Produces)
+	assert.Equal(t, []string{"application/json", "text/xml"}, pathItem.Delete.Produces)
+	assert.Equal(t, []string{"text/html"}, pathItem.Post.Produces)
+	assert.Equal(t, map[string][]string{"api": {"application/json", "text/xml"}}, p.producesByPackage)
+
+	// package doc comments must not contradict each other
+	otherDoc, err := goparser.ParseFile(token.NewFileSet(), "other.go", `
+// @Produce html
+package api
+`, goparser.ParseComments)
+	assert.NoError(t, err)
+	p = New()
+	p.packages.CollectAstFile("api", "api/doc.go", docFile)
+	p.packages.CollectAstFile("api", "api/other.go", otherDoc)
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	err = p.ParseRouterAPIInfo("", f)
+	assert.EqualError(t, err, "ParseComment error in file  :conflicting @Produce in the package doc comments of api/doc.go and api/other.go")
+}
+
+func TestParser_ParseNamedSliceOfStructType(t *testing.T) {
+	src := `
+package api
+
+type Point struct {
+	X int
+	Y int
+}
+
+type Points []Point
+
+type Shape struct {
+	Points Points
+}
+
+// @Success 200 {object} Shape
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Point": {
+      "type": "object",
+      "properties": {
+         "x": {
+            "type": "integer"
+         },
+         "y": {
+            "type": "integer"
+         }
+      }
+   },
+   "api.Shape": {
+      "type": "object",
+      "properties": {
+         "points": {
+            "type": "array",
+            "items": {
+               "$ref": "#/definitions/api.Point"
+            }
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseReadOnlyPatterns(t *testing.T) {
+	src := `
+package api
+
+import "time"
+
+type User struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time ` + "`" + `json:"modified"` + "`" + `
+}
+
+// @Success 200 {object} User
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.User": {
+      "type": "object",
+      "properties": {
+         "createdAt": {
+            "type": "string",
+            "format": "date-time",
+            "readOnly": true
+         },
+         "id": {
+            "type": "integer",
+            "readOnly": true
+         },
+         "modified": {
+            "type": "string",
+            "format": "date-time",
+            "readOnly": true
+         },
+         "name": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	p.ReadOnlyPatterns = []string{"ID", "*At"}
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseParamWithAliasedImport(t *testing.T) {
+	model := `
+package model
+
+type User struct {
+	Name string
+}
+`
+	src := `
+package api
+
+import aliaspkg "github.com/example/model"
+
+// @Param body body aliaspkg.User true "user"
+// @Param filter query aliaspkg.User false "filter"
+// @Router /users [post]
+func CreateUser(){
+}
+`
+	modelFile, err := goparser.ParseFile(token.NewFileSet(), "model.go", model, goparser.ParseComments)
+	assert.NoError(t, err)
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("github.com/example/model", "model/model.go", modelFile)
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	params := p.swagger.Paths.Paths["/users"].Post.Parameters
+	if assert.Len(t, params, 2) {
+		assert.Equal(t, "#/definitions/model.User", params[0].Schema.Ref.String())
+		assert.Equal(t, "name", params[1].Name)
+		assert.Equal(t, "query", params[1].In)
+	}
+	assert.Contains(t, p.swagger.Definitions, "model.User")
+}
+
+func TestParser_ParseStructEmbeddedMutex(t *testing.T) {
+	src := `
+package api
+
+import "sync"
+
+type Cache struct {
+	sync.Mutex
+	*sync.RWMutex
+	Name string
+}
+
+// @Success 200 {object} Cache
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Cache": {
+      "type": "object",
+      "properties": {
+         "name": {
+            "type": "string"
+         }
+      }
+   }
+}`
+
+	p := New()
+	f := parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	// the exported fields of an embedded standard type aren't dropped silently
+	src = `
+package api
+
+import "image"
+
+type Shape struct {
+	image.Point
+	Name string
+}
+
+// @Success 200 {object} Shape
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	f, err = goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p = New()
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.Error(t, p.ParseRouterAPIInfo("", f))
+}
+
+func TestParser_AddMissingTags(t *testing.T) {
+	src := `
+package api
+
+// @Tags users,admin
+// @Router /users [get]
+func ListUsers(){
+}
+
+// @Tags accounts
+// @Router /accounts [get]
+func ListAccounts(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.AutoCreateTags = true
+	p.swagger.Tags = []spec.Tag{spec.NewTag("users", "Operations about users", nil)}
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+	p.addMissingTags()
+
+	out, err := json.Marshal(p.swagger.Tags)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"description":"Operations about users","name":"users"},{"name":"accounts"},{"name":"admin"}]`, string(out))
+}
+
+func TestParser_ParseByteSliceField(t *testing.T) {
+	src := `
+package api
+
+type File struct {
+	Content []byte
+	Chunks  [][]byte
+}
+
+// @Success 200 {object} File
+// @Failure 400 {object} []byte
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.File": {
+      "type": "object",
+      "properties": {
+         "chunks": {
+            "type": "array",
+            "items": {
+               "type": "string",
+               "format": "byte"
+            }
+         },
+         "content": {
+            "type": "string",
+            "format": "byte"
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	schema := p.swagger.Paths.Paths["/api/{id}"].Get.Responses.StatusCodeResponses[400].Schema
+	assert.Equal(t, "string", schema.Type[0])
+	assert.Equal(t, "byte", schema.Format)
+}
+
+func TestParser_ParseTypeDescription(t *testing.T) {
+	src := `
+package api
+
+// User is the user model.
+// @Description A user of the shop,
+// @Description identified by its ID.
+type User struct {
+	ID int
+}
+
+// Admin is a user, too.
+// @Description An administrator.
+type Admin User
+
+// @Success 200 {object} User
+// @Success 201 {object} Admin
+// @Router /api/{id} [get]
+func Test(){
+}
+`
+	expected := `{
+   "api.Admin": {
+      "description": "An administrator.",
+      "type": "object",
+      "properties": {
+         "id": {
+            "type": "integer"
+         }
+      }
+   },
+   "api.User": {
+      "description": "A user of the shop,\nidentified by its ID.",
+      "type": "object",
+      "properties": {
+         "id": {
+            "type": "integer"
+         }
+      }
+   }
+}`
+
+	p := New()
+	parseTestSource(t, p, src)
+
+	out, err := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+}
+
+func TestParser_ParseRouterWithIDAndMultipleRouters(t *testing.T) {
+	src := `
+package api
+
+// @Summary get user
+// @ID get-user
+// @Router /users/{id} [get]
+// @Router /v2/users/{id} [get]
+// @Router /accounts/{id} [get]
+func GetUser(){
+}
+`
+	for i := 0; i < 2; i++ {
+		f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+		assert.NoError(t, err)
+
+		p := New()
+		err = p.ParseRouterAPIInfo("", f)
+		assert.NoError(t, err)
+
+		paths := p.swagger.Paths.Paths
+		assert.Equal(t, "get-user", paths["/users/{id}"].Get.ID)
+		assert.Equal(t, "get-user-2", paths["/v2/users/{id}"].Get.ID)
+		assert.Equal(t, "get-user-3", paths["/accounts/{id}"].Get.ID)
+		assert.Equal(t, "get user", paths["/accounts/{id}"].Get.Summary)
+		assert.NoError(t, p.checkOperationIDUniqueness())
+	}
+}
+
+func TestParser_ParseTransitivelyImportedType(t *testing.T) {
+	model := `
+package model
+
+type User struct {
+	Name string
+}
+`
+	otherModel := `
+package model
+
+type User struct {
+	ID int
+}
+
+type Group struct {
+	Name string
+}
+`
+	src := `
+package api
+
+import "github.com/example/service"
+
+// @Success 200 {object} model.Group
+// @Router /groups [get]
+func ListGroups(){
+	service.ListGroups()
+}
+`
+	modelFile, err := goparser.ParseFile(token.NewFileSet(), "model.go", model, goparser.ParseComments)
+	assert.NoError(t, err)
+	otherModelFile, err := goparser.ParseFile(token.NewFileSet(), "model.go", otherModel, goparser.ParseComments)
+	assert.NoError(t, err)
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("github.com/example/model", "model/model.go", modelFile)
+	p.packages.CollectAstFile("github.com/example/other/model", "other/model/model.go", otherModelFile)
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	typeDef := p.packages.FindTypeSpec("model.Group", f)
+	if assert.NotNil(t, typeDef) {
+		assert.Equal(t, "github.com/example/other/model", typeDef.PkgPath)
+	}
+	// model.User is defined by both packages named model
+	assert.Nil(t, p.packages.FindTypeSpec("model.User", f))
+
+	// without the unique definition, the package is searched by its name
+	delete(p.packages.uniqueDefinitions, "model.Group")
+	assert.Equal(t, typeDef, p.packages.FindTypeSpec("model.Group", f))
+
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+	assert.Contains(t, p.swagger.Definitions, "model.Group")
+}
+
+func TestParser_ParseStructWithoutRequiredFields(t *testing.T) {
+	src := `
+package api
+
+type Pet struct {
+	Name string ` + "`json:\"name\"`" + `
+	Age  int    ` + "`json:\"age,omitempty\"`" + `
+}
+
+// @Success 200 {object} api.Pet
+// @Router /pets [get]
+func ListPets(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	// required is omitempty, so the empty list of a struct without required fields isn't rendered
+	assert.Empty(t, p.swagger.Definitions["api.Pet"].Required)
+	b, err := json.Marshal(p.swagger)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), `"required"`)
+}
+
+func TestParser_ParseMapRegisteredHandlers(t *testing.T) {
+	src := `
+package api
+
+import "net/http"
+
+var handlers = map[string]http.HandlerFunc{
+	// @Summary List pets
+	// @Success 200 {string} string
+	// @Router /pets [get]
+	"/pets": func(w http.ResponseWriter, r *http.Request) {
+		// not an annotation
+	},
+	"/health": func(w http.ResponseWriter, r *http.Request) {},
+	// @Summary Create pet
+	// @Router /pets [post]
+	"/pets/new": func(w http.ResponseWriter, r *http.Request) {},
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	assert.Len(t, p.swagger.Paths.Paths, 1)
+	pets := p.swagger.Paths.Paths["/pets"]
+	if assert.NotNil(t, pets.Get) {
+		assert.Equal(t, "List pets", pets.Get.Summary)
+	}
+	if assert.NotNil(t, pets.Post) {
+		assert.Equal(t, "Create pet", pets.Post.Summary)
+	}
+}
+
+func TestParser_ParseStructFieldTitle(t *testing.T) {
+	src := `
+package api
+
+type Pet struct {
+	Name string ` + "`json:\"name\" title:\"Pet name\"`" + `
+	Age  int    ` + "`json:\"age\"`" + `
+}
+
+// @Success 200 {object} api.Pet
+// @Router /pets [get]
+func ListPets(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	properties := p.swagger.Definitions["api.Pet"].Properties
+	assert.Equal(t, "Pet name", properties["name"].Title)
+	assert.Empty(t, properties["age"].Title)
+}
+
+func TestParser_ParseAPIDiagnostics(t *testing.T) {
+	searchDir := "testdata/diagnostics"
+	mainAPIFile := "main.go"
+	p := New()
+	p.CollectDiagnostics = true
+	err := p.ParseAPI(searchDir, mainAPIFile, defaultParseDepth)
+
+	diagnostics, ok := err.(Diagnostics)
+	if !assert.True(t, ok, "expected Diagnostics, got %v", err) {
+		return
+	}
+	if assert.Len(t, diagnostics, 2) {
+		assert.Equal(t, filepath.Join(searchDir, "api", "api.go"), diagnostics[0].File)
+		assert.Equal(t, 14, diagnostics[0].Line)
+		assert.Equal(t, 1, diagnostics[0].Column)
+		assert.Contains(t, diagnostics[0].Message, "id path int true")
+		assert.Equal(t, 21, diagnostics[1].Line)
+		assert.Contains(t, diagnostics[1].Message, "api.Unknown")
+	}
+
+	// the well-formed operation is still parsed
+	assert.NotNil(t, p.swagger.Paths.Paths["/pets"].Get)
+
+	// without diagnostics, parsing stops at the first malformed annotation
+	err = New().ParseAPI(searchDir, mainAPIFile, defaultParseDepth)
+	assert.Error(t, err)
+	_, ok = err.(Diagnostics)
+	assert.False(t, ok)
+}
+
+func TestParser_ParseFuncLocalTypes(t *testing.T) {
+	src := `
+package api
+
+type Pet struct {
+	Name string
+}
+
+// @Success 200 {object} response
+// @Router /pets [get]
+func ListPets(){
+	type response struct {
+		Pets  []Pet
+		Total int
+	}
+}
+
+// @Success 200 {object} api.response
+// @Router /pets/count [get]
+func CountPets(){
+	type response struct {
+		Count int
+	}
+}
+
+// @Success 200 {object} response
+// @Router /owners [get]
+func ListOwners(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.ParseFuncLocalTypes = true
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	err = p.ParseRouterAPIInfo("", f)
+	assert.EqualError(t, err, "ParseComment error in file  :cannot find type definition: response")
+
+	expected := `{
+   "api.CountPets.response": {
+      "type": "object",
+      "properties": {
+         "count": {
+            "type": "integer"
+         }
+      }
+   },
+   "api.ListPets.response": {
+      "type": "object",
+      "properties": {
+         "pets": {
+            "type": "array",
+            "items": {
+               "$ref": "#/definitions/api.Pet"
+            }
+         },
+         "total": {
+            "type": "integer"
+         }
+      }
+   },
+   "api.Pet": {
+      "type": "object",
+      "properties": {
+         "name": {
+            "type": "string"
+         }
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_ParseConstEnums(t *testing.T) {
+	src := `
+package api
+
+type Status int
+
+const (
+	Active Status = iota + 1
+	Inactive
+	_
+	Deleted
+)
+
+type Color string
+
+const Red Color = "red"
+
+const (
+	Green Color = "green"
+	Blue  Color = "blue"
+	Other       = "other"
+)
+
+type Flag uint8
+
+const (
+	Read = Flag(1 << iota)
+	Write
+)
+
+type Pet struct {
+	Status Status
+	Color  Color
+	Flags  []Flag
+}
+
+// @Success 200 {object} api.Pet
+// @Router /pets [get]
+func ListPets(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	expected := `{
+   "api.Pet": {
+      "type": "object",
+      "properties": {
+         "color": {
+            "type": "string",
+            "enum": [
+               "red",
+               "green",
+               "blue"
+            ],
+            "x-enum-varnames": [
+               "Red",
+               "Green",
+               "Blue"
+            ]
+         },
+         "flags": {
+            "type": "array",
+            "items": {
+               "type": "integer",
+               "enum": [
+                  1,
+                  2
+               ],
+               "x-enum-varnames": [
+                  "Read",
+                  "Write"
+               ]
+            }
+         },
+         "status": {
+            "type": "integer",
+            "enum": [
+               1,
+               2,
+               4
+            ],
+            "x-enum-varnames": [
+               "Active",
+               "Inactive",
+               "Deleted"
+            ]
+         }
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_ParseAmbiguousTypeName(t *testing.T) {
+	model := `
+package model
+
+type Config struct {
+	Name string
+}
+`
+	src := `
+package api
+
+import "github.com/example/a/model"
+
+// @Success 200 {object} model.Config
+// @Router /config [get]
+func GetConfig(){
+}
+`
+	otherSrc := `
+package other
+
+// @Success 200 {object} model.Config
+// @Router /other/config [get]
+func GetOtherConfig(){
+}
+`
+	p := New()
+	for _, pkgPath := range []string{"github.com/example/a/model", "github.com/example/b/model", "github.com/example/c/model"} {
+		modelFile, err := goparser.ParseFile(token.NewFileSet(), "model.go", model, goparser.ParseComments)
+		assert.NoError(t, err)
+		p.packages.CollectAstFile(pkgPath, pkgPath+"/model.go", modelFile)
+	}
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	otherFile, err := goparser.ParseFile(token.NewFileSet(), "other.go", otherSrc, goparser.ParseComments)
+	assert.NoError(t, err)
+	p.packages.CollectAstFile("github.com/example/other", "other/other.go", otherFile)
+
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.NotContains(t, p.packages.uniqueDefinitions, "model.Config")
+
+	// the package qualified reference resolves by its import
+	typeDef := p.packages.FindTypeSpec("model.Config", f)
+	if assert.NotNil(t, typeDef) {
+		assert.Equal(t, "github.com/example/a/model", typeDef.PkgPath)
+	}
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+
+	err = p.ParseRouterAPIInfo("", otherFile)
+	assert.EqualError(t, err, "ParseComment error in file  :ambiguous type definition: model.Config is declared in packages "+
+		"github.com/example/a/model, github.com/example/b/model, github.com/example/c/model, import the one meant")
+}
+
+func TestParser_ParseArrayOfPointers(t *testing.T) {
+	src := `
+package api
+
+type User struct {
+	Name string
+}
+
+type Team struct {
+	Members []*User
+}
+
+// @Success 200 {object} Team
+// @Router /team [get]
+func GetTeam(){
+}
+`
+	for _, openAPI3 := range []bool{false, true} {
+		p := New()
+		p.OpenAPI3 = openAPI3
+		parseTestSource(t, p, src)
+
+		members := p.swagger.Definitions["api.Team"].Properties["members"]
+		assert.Equal(t, spec.StringOrArray{"array"}, members.Type)
+		itemRef := members.Items.Schema.Ref
+		assert.Equal(t, "#/definitions/api.User", itemRef.String())
+		assert.Equal(t, openAPI3, members.Items.Schema.Extensions["x-nullable"] == true)
+		_, ok := members.Extensions["x-nullable"]
+		assert.False(t, ok)
+	}
+}
+
+func TestParser_CollectAstFileBuildConstraints(t *testing.T) {
+	sources := map[string]string{
+		"api/api.go": `
+package api
+
+type User struct {
+	Name string
+}
+`,
+		"api/integration.go": `//go:build integration
+// +build integration
+
+package api
+
+type Fixture struct {
+	Name string
+}
+`,
+		"api/other_os.go": `// +build ` + otherGOOS() + `
+
+package api
+
+type User struct {
+	ID int
+}
+`,
+		"api/ignored.go": `//go:build ignore
+
+package api
+
+type Tool struct {
+	Name string
+}
+`,
+	}
+
+	collect := func(tags []string) map[string]bool {
+		p := New()
+		p.packages.SetBuildTags(tags)
+		for path, src := range sources {
+			f, err := goparser.ParseFile(token.NewFileSet(), path, src, goparser.ParseComments)
+			assert.NoError(t, err)
+			p.packages.CollectAstFile("api", path, f)
+		}
+		collected := make(map[string]bool)
+		_ = p.packages.RangeFiles(func(filename string, file *ast.File) error {
+			collected[filename] = true
+			return nil
+		})
+		return collected
+	}
+
+	assert.Equal(t, map[string]bool{"api/api.go": true}, collect(nil))
+	assert.Equal(t, map[string]bool{"api/api.go": true, "api/integration.go": true}, collect([]string{"integration"}))
+}
+
+func otherGOOS() string {
+	if runtime.GOOS == "windows" {
+		return "linux"
+	}
+	return "windows"
+}
+
+func TestParser_ParseTypesDeterministic(t *testing.T) {
+	for n := 0; n < 20; n++ {
+		p := New()
+		for i := 0; i < 10; i++ {
+			src := fmt.Sprintf(`
+package api
+
+type User struct {
+	Field%d string
+}
+
+type Model%d struct {
+	Name string
+}
+`, i, i)
+			path := fmt.Sprintf("api/file%d.go", i)
+			f, err := goparser.ParseFile(token.NewFileSet(), path, src, goparser.ParseComments)
+			assert.NoError(t, err)
+			p.packages.CollectAstFile("api", path, f)
+		}
+
+		_, err := p.packages.ParseTypes()
+		assert.NoError(t, err)
+		assert.Len(t, p.packages.uniqueDefinitions, 11)
+
+		// the first declaration by path wins
+		user := p.packages.uniqueDefinitions["api.User"]
+		if assert.NotNil(t, user) {
+			assert.Equal(t, "Field0", user.TypeSpec.Type.(*ast.StructType).Fields.List[0].Names[0].Name)
+		}
+	}
+}
+
+func TestParser_ParseCRLFComments(t *testing.T) {
+	src := strings.ReplaceAll(`
+// @title Users API
+// @description Manages users
+// @description of the shop
+// @BasePath /v1
+package api
+
+// @Summary Get a user
+// @Tags users
+// @Param id path int true "User ID"
+// @Success 200 {string} string "ok"
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`, "\n", "\r\n")
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	assert.NoError(t, p.parseGeneralAPIInfoFromPackageDocs(".", "main.go"))
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+
+	assert.Equal(t, "Users API", p.swagger.Info.Title)
+	assert.Equal(t, "Manages users\nof the shop", p.swagger.Info.Description)
+	assert.Equal(t, "/v1", p.swagger.BasePath)
+	operation := p.swagger.Paths.Paths["/users/{id}"].Get
+	if assert.NotNil(t, operation) {
+		assert.Equal(t, "Get a user", operation.Summary)
+		assert.Equal(t, []string{"users"}, operation.Tags)
+		assert.Equal(t, "User ID", operation.Parameters[0].Description)
+	}
+
+	// comments not read by go/parser keep their carriage returns
+	rawOperation := NewOperation(nil)
+	assert.NoError(t, rawOperation.ParseComment("// @Summary Get a user\r", nil))
+	assert.NoError(t, rawOperation.ParseComment("// @Description Gets a user\r", nil))
+	assert.Equal(t, "Get a user", rawOperation.Summary)
+	assert.Equal(t, "Gets a user", rawOperation.Description)
+	assert.Equal(t, []string{"@title Users API", "@BasePath /v1"}, commentLines("@title Users API\r\n@BasePath /v1"))
+}
+
+func TestParser_Exclude(t *testing.T) {
+	searchDir := "testdata/simple"
+
+	p := New(SetExcludedDirsAndFiles("web, cross/*.go"))
+	err := p.getAllGoFileInfo("testdata", searchDir)
+	assert.NoError(t, err)
+
+	var pkgPaths []string
+	for pkgPath := range p.packages.packages {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+	sort.Strings(pkgPaths)
+	assert.Equal(t, []string{"testdata", "testdata/api"}, pkgPaths)
+
+	assert.True(t, p.isExcluded("web/handler.go"))
+	assert.False(t, p.isExcluded("webhook"))
+	assert.False(t, p.isExcluded("cross/sub/model.go"))
+	assert.False(t, p.isExcluded("."))
+
+	// paths like the walked ones still exclude what they name
+	p = New(SetExcludedDirsAndFiles("testdata/simple/web"))
+	assert.NoError(t, p.getAllGoFileInfo("testdata", searchDir))
+	assert.NotContains(t, p.packages.packages, "testdata/web")
+	assert.Contains(t, p.packages.packages, "testdata/cross")
+}
+
+func TestParser_ParseDotImportedTypes(t *testing.T) {
+	typesSrc := `
+package types
+
+const DefaultLimit = 20
+
+type User struct {
+	Name string
+}
+`
+	otherSrc := `
+package other
+
+type User struct {
+	ID int
+}
+`
+	src := `
+package api
+
+import (
+	. "github.com/example/shared/types"
+	. "github.com/example/shared/other"
+)
+
+// @Param limit query int false "limit" default(@DefaultLimit)
+// @Success 200 {object} User
+// @Router /users [get]
+func GetUsers(){
+}
+`
+	p := New()
+	for pkgPath, pkgSrc := range map[string]string{"github.com/example/shared/types": typesSrc, "github.com/example/shared/other": otherSrc} {
+		f, err := goparser.ParseFile(token.NewFileSet(), "", pkgSrc, goparser.ParseComments)
+		assert.NoError(t, err)
+		p.packages.CollectAstFile(pkgPath, pkgPath+"/file.go", f)
+	}
+	f, err := goparser.ParseFile(token.NewFileSet(), "api.go", src, goparser.ParseComments)
+	assert.NoError(t, err)
+	p.packages.CollectAstFile("github.com/example/api", "api/api.go", f)
+	_, err = p.packages.ParseTypes()
+	assert.NoError(t, err)
+
+	// both packages declare User, the first dot import wins
+	typeDef := p.packages.FindTypeSpec("User", f)
+	if assert.NotNil(t, typeDef) {
+		assert.Equal(t, "github.com/example/shared/types", typeDef.PkgPath)
+	}
+
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+	operation := p.swagger.Paths.Paths["/users"].Get
+	assert.Equal(t, 20, operation.Parameters[0].Default)
+	schema := operation.Responses.StatusCodeResponses[200].Schema
+	assert.Equal(t, "#/definitions/types.User", schema.Ref.String())
+}
+
+func TestParser_AddTypeOverride(t *testing.T) {
+	uuidSrc := `
+package uuid
+
+type UUID [16]byte
+`
+	src := `
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	dec "github.com/shopspring/decimal"
+	"github.com/example/go-money/v2"
+)
+
+type Order struct {
+	ID        uuid.UUID
+	Price     dec.Decimal
+	Total     money.Amount
+	CreatedAt time.Time
+	Payload   json.RawMessage
+}
+
+// @Success 200 {object} Order
+// @Router /orders/{id} [get]
+func GetOrder(){
+}
+`
+	expected := `{
+   "api.Order": {
+      "type": "object",
+      "properties": {
+         "createdAt": {
+            "type": "string",
+            "format": "date-time"
+         },
+         "id": {
+            "type": "string",
+            "format": "uuid"
+         },
+         "payload": {},
+         "price": {
+            "type": "string"
+         },
+         "total": {
+            "type": "integer"
+         }
+      }
+   }
+}`
+
+	p := New()
+	uuidSchema := PrimitiveSchema(STRING)
+	uuidSchema.Format = "uuid"
+	p.AddTypeOverride("uuid.UUID", uuidSchema)
+	p.AddTypeOverride("decimal.Decimal", PrimitiveSchema(STRING))
+	// the package of the versioned path is named money
+	p.AddTypeOverride("github.com/example/go-money/v2.Amount", PrimitiveSchema(INTEGER))
+
+	// the vendored copy of the type loses against the override
+	uuidFile, err := goparser.ParseFile(token.NewFileSet(), "", uuidSrc, goparser.ParseComments)
+	assert.NoError(t, err)
+	p.packages.CollectAstFile("github.com/google/uuid", "vendor/github.com/google/uuid/uuid.go", uuidFile)
+	parseTestSource(t, p, src)
+
+	b, _ := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestPackagesDefinitions_importedPackagePath(t *testing.T) {
+	src := `
+package api
+
+import (
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v2"
+	"example.com/lib-go"
+)
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	pkgs := NewPackagesDefinitions()
+	pkgs.packages["example.com/lib-go"] = &PackageDefinitions{Name: "lib"}
+	for pkgName, expected := range map[string]string{
+		"chi":  "github.com/go-chi/chi/v5",
+		"yaml": "gopkg.in/yaml.v2",
+		"lib":  "example.com/lib-go",
+	} {
+		pkgPath, ok := pkgs.importedPackagePath(f, pkgName)
+		assert.True(t, ok)
+		assert.Equal(t, expected, pkgPath)
+	}
+	_, ok := pkgs.importedPackagePath(f, "v5")
+	assert.False(t, ok)
+}
+
+func TestParser_ParseEmbeddedFields(t *testing.T) {
+	src := `
+package api
+
+type Base struct {
+	ID   int    ` + "`" + `json:"id" binding:"required"` + "`" + `
+	Name string ` + "`" + `json:"name" binding:"required"` + "`" + `
+}
+
+type Audit struct {
+	CreatedBy string ` + "`" + `json:"createdBy"` + "`" + `
+}
+
+type Meta struct {
+	Version int ` + "`" + `json:"version"` + "`" + `
+}
+
+type Named interface {
+	GetName() string
+}
+
+type User struct {
+	Base
+	*Audit
+	Meta ` + "`" + `json:"meta"` + "`" + `
+	Named
+	error
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	user := p.swagger.Definitions["api.User"]
+	var names []string
+	for name := range user.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"createdBy", "id", "meta", "name", "named"}, names)
+	// Name of User shadows the required one of Base
+	assert.Equal(t, []string{"id"}, user.Required)
+	meta := user.Properties["meta"]
+	assert.Equal(t, "#/definitions/api.Meta", meta.Ref.String())
+}
+
+func TestParser_ParseReplacedModules(t *testing.T) {
+	t.Parallel()
+
+	searchDir := "testdata/replace/api"
+	p := New()
+	assert.NoError(t, p.getAllGoFileInfoFromReplaces(searchDir))
+	assert.True(t, p.isReplacedPackage("github.com/example/shared/model"))
+	assert.False(t, p.isReplacedPackage("github.com/example/sharedmodel"))
+
+	assert.NoError(t, p.getAllGoFileInfo("github.com/example/api", searchDir))
+	_, err := p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.NoError(t, p.packages.RangeFiles(p.ParseRouterAPIInfo))
+
+	response := p.swagger.Paths.Paths["/users/{id}"].Get.Responses.StatusCodeResponses[200]
+	assert.Equal(t, "#/definitions/model.User", response.Schema.Ref.String())
+	user := p.swagger.Definitions["model.User"]
+	assert.Contains(t, user.Properties, "id")
+	assert.Contains(t, user.Properties, "name")
+}
+
+func TestParseLocalReplaces(t *testing.T) {
+	t.Parallel()
+
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	goMod := `module github.com/example/api
+
+replace github.com/example/a => ../a // local
+
+replace (
+	github.com/example/b v1.0.0 => ./b
+	github.com/example/c => github.com/fork/c v1.2.0
+)
+`
+	assert.NoError(t, ioutil.WriteFile(goModPath, []byte(goMod), 0644))
+
+	replaces, err := parseLocalReplaces(goModPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"github.com/example/a": filepath.Join(filepath.Dir(goModPath), "..", "a"),
+		"github.com/example/b": filepath.Join(filepath.Dir(goModPath), "b"),
+	}, replaces)
+	assert.NoError(t, ioutil.WriteFile(goModPath, []byte("module github.com/example/api\nreplace =>\n"), 0644))
+	_, err = parseLocalReplaces(goModPath)
+	assert.Error(t, err)
+}
+
+func TestParser_ParseRecursiveTypes(t *testing.T) {
+	t.Parallel()
+
+	srcs := map[string]string{
+		"x/model": `package model
+import other "y/model"
+
+type Node struct {
+	Children []*Node ` + "`" + `json:"children"` + "`" + `
+	Other *other.Node ` + "`" + `json:"other"` + "`" + `
+}
+
+type A struct {
+	B []B ` + "`" + `json:"b"` + "`" + `
+}
+
+type B struct {
+	A *A ` + "`" + `json:"a"` + "`" + `
+}
+`,
+		"y/model": `package model
+import other "x/model"
+
+type Node struct {
+	Children []Node ` + "`" + `json:"children"` + "`" + `
+	Parent *other.Node ` + "`" + `json:"parent"` + "`" + `
+}
+`,
+		"api": `package api
+import (
+	"x/model"
+	other "y/model"
+)
+
+// @Success 200 {object} model.Node
+// @Router /x [get]
+func GetX(){
+}
+
+// @Success 200 {object} other.Node
+// @Router /y [get]
+func GetY(){
+}
+
+// @Success 200 {object} model.A
+// @Router /a [get]
+func GetA(){
+}
+`,
+	}
+
+	p := New()
+	for _, pkgPath := range []string{"x/model", "y/model", "api"} {
+		f, err := goparser.ParseFile(token.NewFileSet(), "", srcs[pkgPath], goparser.ParseComments)
+		assert.NoError(t, err)
+		p.packages.CollectAstFile(pkgPath, pkgPath+"/model.go", f)
+	}
+	_, err := p.packages.ParseTypes()
+	assert.NoError(t, err)
+	assert.NoError(t, p.packages.RangeFiles(p.ParseRouterAPIInfo))
+	p.renameRefSchemas()
+
+	responseRef := func(path string) string {
+		response := p.swagger.Paths.Paths[path].Get.Responses.StatusCodeResponses[200]
+		return response.Schema.Ref.String()
+	}
+	assert.Equal(t, "#/definitions/x_model.Node", responseRef("/x"))
+	assert.Equal(t, "#/definitions/y_model.Node", responseRef("/y"))
+	assert.Equal(t, "#/definitions/model.A", responseRef("/a"))
+
+	refOf := func(definition, property string) string {
+		schema := p.swagger.Definitions[definition].Properties[property]
+		if schema.Items != nil {
+			return schema.Items.Schema.Ref.String()
+		}
+		// a nullable reference of a pointer
+		return schema.AllOf[0].Ref.String()
+	}
+	assert.Equal(t, "#/definitions/x_model.Node", refOf("x_model.Node", "children"))
+	assert.Equal(t, "#/definitions/y_model.Node", refOf("x_model.Node", "other"))
+	assert.Equal(t, "#/definitions/y_model.Node", refOf("y_model.Node", "children"))
+	assert.Equal(t, "#/definitions/x_model.Node", refOf("y_model.Node", "parent"))
+	assert.Equal(t, "#/definitions/model.B", refOf("model.A", "b"))
+	assert.Equal(t, "#/definitions/model.A", refOf("model.B", "a"))
+}
+
+func TestParser_ParsePackage(t *testing.T) {
+	t.Parallel()
+
+	p := New()
+	schemas, err := p.ParsePackage("testdata/parse_package/base")
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 2)
+	assert.Equal(t, spec.StringOrArray{STRING}, schemas["Status"].Schema.Type)
+	assert.Equal(t, []interface{}{"active", "deleted"}, schemas["Status"].Schema.Enum)
+	assert.Contains(t, schemas["Audit"].Schema.Properties, "createdBy")
+
+	// the types of the package parsed before are found
+	schemas, err = p.ParsePackage("testdata/parse_package/model")
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 2)
+	user := schemas["User"]
+	assert.Equal(t, "model.User", user.Name)
+	audit := user.Schema.Properties["audit"]
+	assert.Equal(t, "#/definitions/base.Audit", audit.Ref.String())
+	pets := user.Schema.Properties["pets"]
+	assert.Equal(t, "#/definitions/model.Pet", pets.Items.Schema.Ref.String())
+	assert.Contains(t, p.swagger.Definitions, "base.Audit")
+	assert.Contains(t, p.swagger.Definitions, "model.Pet")
+	assert.Contains(t, p.packages.uniqueDefinitions, "base.Audit")
+	assert.Contains(t, p.packages.uniqueDefinitions, "model.User")
+}
+
+func TestParser_ParsePointerFields(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Profile struct {
+	Bio string ` + "`" + `json:"bio"` + "`" + `
+}
+
+type User struct {
+	Nickname *string ` + "`" + `json:"nickname,omitempty" binding:"required"` + "`" + `
+	Email *string ` + "`" + `json:"email" validate:"required"` + "`" + `
+	Profile *Profile ` + "`" + `json:"profile"` + "`" + `
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	expected := `{
+   "type": "object",
+   "required": [
+      "email",
+      "nickname"
+   ],
+   "properties": {
+      "email": {
+         "type": "string",
+         "x-nullable": true
+      },
+      "name": {
+         "type": "string"
+      },
+      "nickname": {
+         "type": "string",
+         "x-nullable": true
+      },
+      "profile": {
+         "allOf": [
+            {
+               "$ref": "#/definitions/api.Profile"
+            }
+         ],
+         "x-nullable": true
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.User"], "", "   ")
+	assert.Equal(t, expected, string(b))
+
+	b, err := json.Marshal(ConvertToOpenAPI3(p.swagger).Components.Schemas["api.User"].Properties["profile"])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"allOf":[{"$ref":"#/components/schemas/api.Profile"}],"nullable":true}`, string(b))
+}
+
+func TestParser_ParseNullableOmitEmpty(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type User struct {
+	Nickname *string ` + "`" + `json:"nickname,omitempty" binding:"required"` + "`" + `
+	Email *string ` + "`" + `json:"email" binding:"required"` + "`" + `
+	Name string ` + "`" + `json:"name,omitempty" binding:"required"` + "`" + `
+}
+
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`
+	for _, enabled := range []bool{false, true} {
+		p := New()
+		p.NullableOmitEmpty = enabled
+		parseTestSource(t, p, src)
+
+		user := p.swagger.Definitions["api.User"]
+		nickname := user.Properties["nickname"]
+		assert.Equal(t, spec.StringOrArray{"string"}, nickname.Type)
+		assert.Equal(t, true, nickname.Extensions["x-nullable"])
+		if enabled {
+			assert.Equal(t, []string{"email"}, user.Required)
+		} else {
+			assert.Equal(t, []string{"email", "nickname"}, user.Required)
+		}
+	}
+}
+
+func TestParser_ParseArrayOfAny(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Payload struct {
+	Values []any ` + "`" + `json:"values"` + "`" + `
+	Items []interface{} ` + "`" + `json:"items"` + "`" + `
+	Meta map[string]any ` + "`" + `json:"meta"` + "`" + `
+	Data any ` + "`" + `json:"data"` + "`" + `
+}
+
+// @Success 200 {object} Payload
+// @Router /payload [get]
+func GetPayload(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	expected := `{
+   "type": "object",
+   "properties": {
+      "data": {
+         "type": "object"
+      },
+      "items": {
+         "type": "array",
+         "items": {}
+      },
+      "meta": {
+         "type": "object",
+         "additionalProperties": true
+      },
+      "values": {
+         "type": "array",
+         "items": {}
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.Payload"], "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_ParseJSONTagOptions(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Base struct {
+	Internal string
+}
+
+type User struct {
+	Base ` + "`" + `json:"-"` + "`" + `
+	Password string ` + "`" + `json:"-"` + "`" + `
+	Dash string ` + "`" + `json:"-,"` + "`" + `
+	FullName string ` + "`" + `json:"full_name" binding:"required"` + "`" + `
+	Nickname string ` + "`" + `json:"nickname,omitempty" binding:"required"` + "`" + `
+	Count int ` + "`" + `json:"count,string"` + "`" + `
+	Age int
+}
+
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	expected := `{
+   "type": "object",
+   "required": [
+      "full_name"
+   ],
+   "properties": {
+      "-": {
+         "type": "string"
+      },
+      "age": {
+         "type": "integer"
+      },
+      "count": {
+         "type": "string",
+         "example": "0"
+      },
+      "full_name": {
+         "type": "string"
+      },
+      "nickname": {
+         "type": "string"
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.User"], "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_ParseValidateTags(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type User struct {
+	Name string ` + "`" + `json:"name" validate:"required,min=3,max=64"` + "`" + `
+	Email string ` + "`" + `json:"email" validate:"omitempty,email"` + "`" + `
+	ID string ` + "`" + `json:"id" validate:"uuid4" format:"guid"` + "`" + `
+	Age int ` + "`" + `json:"age" validate:"gte=18,lte=130" maximum:"120"` + "`" + `
+	Color string ` + "`" + `json:"color" validate:"oneof=red 'dark blue'"` + "`" + `
+	Level int ` + "`" + `json:"level" validate:"oneof=1 2 3,custom=x,min=abc"` + "`" + `
+	Tags []string ` + "`" + `json:"tags" validate:"max=5,dive,min=2"` + "`" + `
+	Code string ` + "`" + `json:"code" binding:"len=4"` + "`" + `
+}
+
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func GetUser(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	expected := `{
+   "type": "object",
+   "required": [
+      "name"
+   ],
+   "properties": {
+      "age": {
+         "type": "integer",
+         "maximum": 120,
+         "minimum": 18
+      },
+      "code": {
+         "type": "string"
+      },
+      "color": {
+         "type": "string",
+         "enum": [
+            "red",
+            "dark blue"
+         ]
+      },
+      "email": {
+         "type": "string",
+         "format": "email"
+      },
+      "id": {
+         "type": "string",
+         "format": "guid"
+      },
+      "level": {
+         "type": "integer",
+         "enum": [
+            1,
+            2,
+            3
+         ]
+      },
+      "name": {
+         "type": "string",
+         "maxLength": 64,
+         "minLength": 3
+      },
+      "tags": {
+         "type": "array",
+         "maxItems": 5,
+         "items": {
+            "type": "string",
+            "minLength": 2
+         }
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.User"], "", "   ")
+	assert.Equal(t, expected, string(b))
+
+	// the rules of Gin's binding tag
+	p = New()
+	p.ValidateTagName = "binding"
+	parseTestSource(t, p, src)
+
+	definition := p.swagger.Definitions["api.User"]
+	code := definition.Properties["code"]
+	assert.Equal(t, int64(4), *code.MinLength)
+	assert.Equal(t, int64(4), *code.MaxLength)
+	name := definition.Properties["name"]
+	assert.Nil(t, name.MinLength)
+}
+
+func TestParser_ParseWriteOnlyPatterns(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Credentials struct {
+	Login string ` + "`" + `json:"login"` + "`" + `
+	Password string ` + "`" + `json:"password"` + "`" + `
+	APISecret string ` + "`" + `json:"api_secret"` + "`" + `
+}
+
+// @Param credentials body Credentials true "credentials"
+// @Router /login [post]
+func Login(){
+}
+`
+	p := New()
+	p.WriteOnlyPatterns = []string{"Password", "*_secret"}
+	parseTestSource(t, p, src)
+
+	credentials := p.swagger.Definitions["api.Credentials"]
+	login := credentials.Properties["login"]
+	assert.Nil(t, login.Extensions["x-writeOnly"])
+	password := credentials.Properties["password"]
+	assert.Equal(t, true, password.Extensions["x-writeOnly"])
+	secret := credentials.Properties["api_secret"]
+	assert.Equal(t, true, secret.Extensions["x-writeOnly"])
+
+	b, err := json.Marshal(ConvertToOpenAPI3(p.swagger).Components.Schemas["api.Credentials"].Properties["password"])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"string","format":"password","writeOnly":true}`, string(b))
+}
+
+func TestParser_ParseEnumRefs(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Status string
+
+const (
+	Active Status = "active"
+	Deleted Status = "deleted"
+)
+
+type User struct {
+	Status Status ` + "`" + `json:"status"` + "`" + `
+	PreviousStatus Status ` + "`" + `json:"previous_status"` + "`" + `
+}
+
+type Filter struct {
+	Status Status ` + "`" + `json:"status"` + "`" + `
+}
+
+// @Param filter query Filter false "filter"
+// @Success 200 {object} User
+// @Router /users [get]
+func ListUsers(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.EnumRefs = true
+	p.packages.CollectAstFile("api", "api/api.go", f)
+	parsedSchemas, err := p.packages.ParseTypes()
+	assert.NoError(t, err)
+	for typeSpecDef, schema := range parsedSchemas {
+		p.parsedSchemas[typeSpecDef] = schema
+	}
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	user := p.swagger.Definitions["api.User"]
+	status := user.Properties["status"]
+	assert.Equal(t, "#/definitions/api.Status", status.Ref.String())
+	previousStatus := user.Properties["previous_status"]
+	assert.Equal(t, "#/definitions/api.Status", previousStatus.Ref.String())
+	assert.Equal(t, []interface{}{"active", "deleted"}, p.swagger.Definitions["api.Status"].Enum)
+
+	// query params can't refer to definitions
+	params := p.swagger.Paths.Paths["/users"].Get.Parameters
+	assert.Len(t, params, 1)
+	assert.Equal(t, "string", params[0].Type)
+	assert.Equal(t, []interface{}{"active", "deleted"}, params[0].Enum)
+}
+
+func TestParser_ParseMapsWithNonStringKeys(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Key int
+
+type User struct {
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+type Index struct {
+	ByID map[int]User ` + "`" + `json:"by_id"` + "`" + `
+	ByKey map[Key]*User ` + "`" + `json:"by_key"` + "`" + `
+	Meta map[Key]interface{} ` + "`" + `json:"meta"` + "`" + `
+}
+
+// @Success 200 {object} Index
+// @Router /index [get]
+func GetIndex(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	index := p.swagger.Definitions["api.Index"]
+	byID := index.Properties["by_id"]
+	assert.Equal(t, "#/definitions/api.User", byID.AdditionalProperties.Schema.Ref.String())
+	byKey := index.Properties["by_key"]
+	assert.Equal(t, "#/definitions/api.User", byKey.AdditionalProperties.Schema.Ref.String())
+	meta := index.Properties["meta"]
+	assert.True(t, meta.AdditionalProperties.Allows)
+	assert.Nil(t, meta.AdditionalProperties.Schema)
+}
+
+func TestParser_SelectMainAPIFileByBuildTags(t *testing.T) {
+	t.Parallel()
+
+	searchDir := "testdata/build_tags_main"
+	for _, tags := range [][]string{nil, {"prod"}} {
+		p := New()
+		p.BuildTags = tags
+		err := p.ParseAPI(searchDir, "main.go", defaultParseDepth)
+		assert.NoError(t, err)
+
+		if len(tags) == 0 {
+			assert.Equal(t, "Development API", p.swagger.Info.Title)
+			assert.Equal(t, "localhost:8080", p.swagger.Host)
+		} else {
+			assert.Equal(t, "Production API", p.swagger.Info.Title)
+			assert.Equal(t, "api.example.com", p.swagger.Host)
+		}
+		assert.Contains(t, p.swagger.Paths.Paths, "/status")
+	}
+
+	// a main file which isn't part of the build is replaced by the one which is
+	p := New()
+	p.packages.SetBuildTags([]string{"prod"})
+	mainAPIFile, err := p.selectMainAPIFile(filepath.Join(searchDir, "main_dev.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(searchDir, "main_prod.go"), mainAPIFile)
+}
+
+func TestParser_ParseExampleAndDefaultTags(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Owner struct {
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+type Settings struct {
+	Count int ` + "`" + `json:"count" example:"42" default:"1"` + "`" + `
+	Status string ` + "`" + `json:"status" example:"active" default:"active"` + "`" + `
+	Enabled bool ` + "`" + `json:"enabled" default:"true"` + "`" + `
+	IDs []int ` + "`" + `json:"ids" example:"[1,2,3]" default:"4,5"` + "`" + `
+	Labels map[string]string ` + "`" + `json:"labels" example:"{\"env\":\"prod\"}"` + "`" + `
+	Owner Owner ` + "`" + `json:"owner" example:"{\"name\":\"jane\"}"` + "`" + `
+}
+
+// @Success 200 {object} Settings
+// @Router /settings [get]
+func GetSettings(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	settings := p.swagger.Definitions["api.Settings"]
+	assert.Equal(t, 42, settings.Properties["count"].Example)
+	assert.Equal(t, 1, settings.Properties["count"].Default)
+	assert.Equal(t, "active", settings.Properties["status"].Example)
+	assert.Equal(t, true, settings.Properties["enabled"].Default)
+	assert.Equal(t, []interface{}{1, 2, 3}, settings.Properties["ids"].Example)
+	assert.Equal(t, []interface{}{4, 5}, settings.Properties["ids"].Default)
+	assert.Equal(t, map[string]interface{}{"env": "prod"}, settings.Properties["labels"].Example)
+	assert.Equal(t, map[string]interface{}{"name": "jane"}, settings.Properties["owner"].Example)
+
+	for _, field := range []string{
+		"Count int `example:\"abc\"`",
+		"Count int `default:\"abc\"`",
+		"IDs []int `example:\"[1,\\\"a\\\"]\"`",
+	} {
+		src := "package api\n\ntype Invalid struct {\n\t" + field + "\n}\n\n// @Success 200 {object} Invalid\n// @Router /invalid [get]\nfunc GetInvalid(){\n}\n"
+		f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+		assert.NoError(t, err)
+
+		p := New()
+		p.packages.CollectAstFile("api", "api/api.go", f)
+		_, err = p.packages.ParseTypes()
+		assert.NoError(t, err)
+		err = p.ParseRouterAPIInfo("", f)
+		assert.Error(t, err, field)
+	}
+}
+
+func TestParser_ParseNameAnnotationInDoc(t *testing.T) {
+	t.Parallel()
+
+	userSrc := `
+package user
+
+// Response the user of a request
+// @name UserResponse
+type Response struct {
+	Name string
+}
+`
+	orderSrc := `
+package order
+
+type Response struct {
+	ID int
+} // @name %s
+`
+	apiSrc := `
+package api
+
+import (
+	"example.com/order"
+	"example.com/user"
+)
+
+// @Success 200 {object} user.Response
+// @Failure 400 {object} order.Response
+// @Router /users [get]
+func GetUser(){
+}
+`
+	parse := func(orderName string) (*Parser, error) {
+		p := New()
+		var api *ast.File
+		for _, file := range []struct{ pkgPath, path, src string }{
+			{"example.com/user", "user/user.go", userSrc},
+			{"example.com/order", "order/order.go", fmt.Sprintf(orderSrc, orderName)},
+			{"api", "api/api.go", apiSrc},
+		} {
+			f, err := goparser.ParseFile(token.NewFileSet(), "", file.src, goparser.ParseComments)
+			assert.NoError(t, err)
+			p.packages.CollectAstFile(file.pkgPath, file.path, f)
+			api = f
+		}
+		_, err := p.packages.ParseTypes()
+		assert.NoError(t, err)
+
+		return p, p.ParseRouterAPIInfo("api/api.go", api)
+	}
+
+	p, err := parse("OrderResponse")
+	assert.NoError(t, err)
+	assert.Contains(t, p.swagger.Definitions, "UserResponse")
+	assert.Contains(t, p.swagger.Definitions, "OrderResponse")
+	assert.NotContains(t, p.swagger.Definitions, "user.Response")
+
+	_, err = parse("UserResponse")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "user/user.go")
+		assert.Contains(t, err.Error(), "order/order.go")
+		assert.Contains(t, err.Error(), "UserResponse")
+	}
+}
+
+func TestParser_ParseInterfaceTypes(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Animal interface {
+	Sound() string
+}
+
+type Zoo struct {
+	Star Animal
+	Animals []Animal
+	Keeper interface{ Name() string }
+	Err error
+}
+
+// @Success 200 {object} Zoo
+// @Failure 400 {object} Animal
+// @Router /zoo [get]
+func GetZoo(){
+}
+`
+	expected := `{
+   "api.Animal": {
+      "type": "object"
+   },
+   "api.Zoo": {
+      "type": "object",
+      "properties": {
+         "animals": {
+            "type": "array",
+            "items": {
+               "$ref": "#/definitions/api.Animal"
+            }
+         },
+         "err": {
+            "type": "object"
+         },
+         "keeper": {
+            "type": "object"
+         },
+         "star": {
+            "$ref": "#/definitions/api.Animal"
+         }
+      }
+   }
+}`
+	p := New()
+	parseTestSource(t, p, src)
+
+	b, _ := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_ParsePasswordFormat(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Account struct {
+	Password string ` + "`" + `json:"password" format:"password"` + "`" + `
+	Token string ` + "`" + `json:"token" writeonly:"true"` + "`" + `
+	PIN int ` + "`" + `json:"pin" writeonly:"true"` + "`" + `
+	Key string ` + "`" + `json:"key" writeonly:"true" format:"byte"` + "`" + `
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+// @Param account body Account true "account"
+// @Router /accounts [post]
+func CreateAccount(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	account := p.swagger.Definitions["api.Account"]
+	assert.Equal(t, "password", account.Properties["password"].Format)
+	assert.Nil(t, account.Properties["password"].Extensions["x-writeOnly"])
+	assert.Equal(t, "password", account.Properties["token"].Format)
+	assert.Equal(t, true, account.Properties["token"].Extensions["x-writeOnly"])
+	assert.Empty(t, account.Properties["pin"].Format)
+	assert.Equal(t, true, account.Properties["pin"].Extensions["x-writeOnly"])
+	assert.Equal(t, "byte", account.Properties["key"].Format)
+	assert.Empty(t, account.Properties["name"].Format)
+}
+
+func TestPackagesDefinitions_RangeFilesInOrder(t *testing.T) {
+	t.Parallel()
+
+	files := []struct{ pkgPath, path string }{
+		{"example.com/b", "b/b.go"},
+		{"example.com/a", "a/z.go"},
+		{"example.com/c", "a/c.go"},
+		{"example.com/a", "a/a.go"},
+	}
+	pkgs := NewPackagesDefinitions()
+	for _, file := range files {
+		f, err := goparser.ParseFile(token.NewFileSet(), file.path, "package "+filepath.Base(file.pkgPath), goparser.ParseComments)
+		assert.NoError(t, err)
+		pkgs.CollectAstFile(file.pkgPath, file.path, f)
+	}
+
+	for i := 0; i < 5; i++ {
+		var paths []string
+		assert.NoError(t, pkgs.RangeFiles(func(filename string, file *ast.File) error {
+			paths = append(paths, filename)
+			return nil
+		}))
+		assert.Equal(t, []string{"a/a.go", "a/z.go", "b/b.go", "a/c.go"}, paths)
+	}
+}
+
+func TestParser_ParsePlatformFiles(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []struct {
+		goos, goarch string
+		property     string
+	}{
+		{"linux", "amd64", "cgroup"},
+		{"android", "arm64", "cgroup"},
+		{"windows", "amd64", "handle"},
+		{"darwin", "arm64", "translated"},
+	} {
+		p := New()
+		p.GOOS, p.GOARCH = target.goos, target.goarch
+		err := p.ParseAPI("testdata/platform", "main.go", defaultParseDepth)
+		assert.NoError(t, err, target.goos+"/"+target.goarch)
+
+		process := p.swagger.Definitions["main.Process"]
+		assert.Len(t, process.Properties, 2, target.goos+"/"+target.goarch)
+		assert.Contains(t, process.Properties, target.property, target.goos+"/"+target.goarch)
+	}
+
+	f, err := goparser.ParseFile(token.NewFileSet(), "", "package main", goparser.ParseComments)
+	assert.NoError(t, err)
+	pkgs := NewPackagesDefinitions()
+	pkgs.SetTarget("darwin", "amd64")
+	for name, expected := range map[string]bool{
+		"linux.go":                true,
+		"process_darwin.go":       true,
+		"process_darwin_arm64.go": false,
+		"process_amd64_test.go":   true,
+		"process_ios.go":          false,
+		"process_darwin_x.go":     true,
+		"process_plan9.go":        false,
+	} {
+		assert.Equal(t, expected, pkgs.matchFile(name, f), name)
+	}
+}
+
+func TestParser_ParsePointerRefs(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type User struct {
+	Name string
+}
+
+type UserPointer *User
+
+type UserPointerAlias = *User
+
+type Team struct {
+	Lead User
+	Deputy *User
+	Members []User
+	Guests []*User
+	ByName map[string]*User
+	Owner UserPointer
+	Coach UserPointerAlias
+}
+
+// @Param user body *User true "user"
+// @Success 200 {object} Team
+// @Success 201 {object} *User
+// @Success 202 {array} *User
+// @Router /teams [post]
+func CreateTeam(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	userRef := "#/definitions/api.User"
+	assert.Equal(t, []string{"api.Team", "api.User"}, definitionNames(p.swagger.Definitions))
+
+	team := p.swagger.Definitions["api.Team"]
+	for _, name := range []string{"lead", "owner", "coach"} {
+		property := team.Properties[name]
+		assert.Equal(t, userRef, property.Ref.String(), name)
+	}
+	deputy := team.Properties["deputy"]
+	assert.Equal(t, userRef, deputy.AllOf[0].Ref.String())
+	for _, name := range []string{"members", "guests"} {
+		property := team.Properties[name]
+		assert.Equal(t, userRef, property.Items.Schema.Ref.String(), name)
+	}
+	byName := team.Properties["byName"]
+	assert.Equal(t, userRef, byName.AdditionalProperties.Schema.Ref.String())
+
+	operation := p.swagger.Paths.Paths["/teams"].Post
+	assert.Equal(t, userRef, operation.Parameters[0].Schema.Ref.String())
+	assert.Equal(t, userRef, operation.Responses.StatusCodeResponses[201].Schema.Ref.String())
+	assert.Equal(t, userRef, operation.Responses.StatusCodeResponses[202].Schema.Items.Schema.Ref.String())
+}
+
+func definitionNames(definitions spec.Definitions) []string {
+	keys := make([]string, 0, len(definitions))
+	for key := range definitions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestParser_ParseSwaggerTypeFormat(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Money struct {
+	Units int64
+	Currency string
+}
+
+type Order struct {
+	Total Money ` + "`" + `json:"total" swaggertype:"integer,int64"` + "`" + `
+	Discounts []Money ` + "`" + `json:"discounts" swaggertype:"array,integer,int64"` + "`" + `
+	Internal Money ` + "`" + `json:"internal" swaggerignore:"true"` + "`" + `
+}
+
+// @Success 200 {object} Order
+// @Router /orders/{id} [get]
+func GetOrder(){
+}
+`
+	expected := `{
+   "api.Order": {
+      "type": "object",
+      "properties": {
+         "discounts": {
+            "type": "array",
+            "items": {
+               "type": "integer",
+               "format": "int64"
+            }
+         },
+         "total": {
+            "type": "integer",
+            "format": "int64"
+         }
+      }
+   }
+}`
+	p := New()
+	parseTestSource(t, p, src)
+
+	b, _ := json.MarshalIndent(p.swagger.Definitions, "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_ParseLazyDependencies(t *testing.T) {
+	t.Parallel()
+
+	parse := func(prefixes ...string) (*Parser, map[string]int, error) {
+		found := map[string]int{}
+		p := New()
+		p.DependencyPrefixes = prefixes
+		p.findPackageDir = func(importPath, srcDir string) (string, error) {
+			found[importPath]++
+			return filepath.Join("testdata/lazy_deps", strings.TrimPrefix(importPath, "github.com/example/")), nil
+		}
+		err := p.ParseAPI("testdata/lazy_deps/api", "main.go", defaultParseDepth)
+		return p, found, err
+	}
+
+	p, found, err := parse("github.com/example/")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"github.com/example/money": 1, "github.com/example/currency": 1}, found)
+	assert.Equal(t, []string{"currency.Currency", "main.Total", "money.Price"}, definitionNames(p.swagger.Definitions))
+	price := p.swagger.Definitions["money.Price"]
+	currency := price.Properties["currency"]
+	assert.Equal(t, "#/definitions/currency.Currency", currency.Ref.String())
+
+	_, found, err = parse("github.com/example/money")
+	assert.EqualError(t, err, "ParseComment error in file testdata/lazy_deps/api/main.go :cannot find type definition: currency.Currency")
+	assert.Equal(t, map[string]int{"github.com/example/money": 1}, found)
+
+	_, found, err = parse()
+	assert.Error(t, err)
+	assert.Empty(t, found)
+
+	for pkgPath, expected := range map[string]string{
+		"github.com/shopspring/decimal": "decimal",
+		"github.com/go-openapi/spec":    "spec",
+		"gopkg.in/yaml.v2":              "yaml",
+		"github.com/jackc/pgx/v4":       "pgx",
+		"github.com/mattn/go-sqlite3":   "sqlite3",
+		"v2":                            "v2",
+	} {
+		assert.Equal(t, expected, importedPackageName(pkgPath), pkgPath)
+	}
+}
+
+func TestParser_ParseEmbeddedAllOf(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Base struct {
+	ID int ` + "`" + `json:"id" binding:"required"` + "`" + `
+}
+
+type Audit struct {
+	CreatedBy string ` + "`" + `json:"createdBy"` + "`" + `
+}
+
+type Meta struct {
+	Version int ` + "`" + `json:"version"` + "`" + `
+}
+
+type User struct {
+	Base
+	*Audit
+	Meta ` + "`" + `json:"meta"` + "`" + `
+	Name string ` + "`" + `json:"name" binding:"required"` + "`" + `
+}
+
+type Admin struct {
+	User
+}
+
+// @Success 200 {object} Admin
+// @Router /admins/{id} [get]
+func GetAdmin(){
+}
+`
+	expected := `{
+   "type": "object",
+   "allOf": [
+      {
+         "$ref": "#/definitions/api.Base"
+      },
+      {
+         "$ref": "#/definitions/api.Audit"
+      },
+      {
+         "type": "object",
+         "required": [
+            "name"
+         ],
+         "properties": {
+            "meta": {
+               "$ref": "#/definitions/api.Meta"
+            },
+            "name": {
+               "type": "string"
+            }
+         }
+      }
+   ]
+}`
+	p := New()
+	p.EmbeddedMode = EmbeddedAllOf
+	parseTestSource(t, p, src)
+
+	assert.Equal(t, []string{"api.Admin", "api.Audit", "api.Base", "api.Meta", "api.User"}, definitionNames(p.swagger.Definitions))
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.User"], "", "   ")
+	assert.Equal(t, expected, string(b))
+	admin := p.swagger.Definitions["api.Admin"]
+	assert.Len(t, admin.AllOf, 1)
+	assert.Equal(t, "#/definitions/api.User", admin.AllOf[0].Ref.String())
+	assert.Empty(t, admin.Properties)
+}
+
+func TestParser_ParseValidateDiveTags(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+type Post struct {
+	Tags []string ` + "`" + `json:"tags" validate:"required,min=1,max=10,unique,dive,required,min=2,max=32"` + "`" + `
+	Scores []int ` + "`" + `json:"scores" validate:"len=3,dive,gte=0,lte=100"` + "`" + `
+	Emails []string ` + "`" + `json:"emails" validate:"dive,email"` + "`" + `
+	States []string ` + "`" + `json:"states" validate:"oneof=a b,dive,oneof=draft published"` + "`" + `
+	Matrix [][]int ` + "`" + `json:"matrix" validate:"max=4,dive,max=4,dive,min=1"` + "`" + `
+	Labels map[string]string ` + "`" + `json:"labels" validate:"dive,max=8"` + "`" + `
+}
+
+// @Success 200 {object} Post
+// @Router /posts/{id} [get]
+func GetPost(){
+}
+`
+	p := New()
+	parseTestSource(t, p, src)
+
+	expected := `{
+   "type": "object",
+   "required": [
+      "tags"
+   ],
+   "properties": {
+      "emails": {
+         "type": "array",
+         "items": {
+            "type": "string",
+            "format": "email"
+         }
+      },
+      "labels": {
+         "type": "object",
+         "additionalProperties": {
+            "type": "string"
+         }
+      },
+      "matrix": {
+         "type": "array",
+         "maxItems": 4,
+         "items": {
+            "type": "array",
+            "items": {
+               "type": "integer"
+            }
+         }
+      },
+      "scores": {
+         "type": "array",
+         "maxItems": 3,
+         "minItems": 3,
+         "items": {
+            "type": "integer",
+            "maximum": 100,
+            "minimum": 0
+         }
+      },
+      "states": {
+         "type": "array",
+         "items": {
+            "type": "string",
+            "enum": [
+               "draft",
+               "published"
+            ]
+         }
+      },
+      "tags": {
+         "type": "array",
+         "maxItems": 10,
+         "minItems": 1,
+         "uniqueItems": true,
+         "items": {
+            "type": "string",
+            "maxLength": 32,
+            "minLength": 2
+         }
+      }
+   }
+}`
+	b, _ := json.MarshalIndent(p.swagger.Definitions["api.Post"], "", "   ")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestParser_InlineResponseName(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+// @ID getUser
+// @Success 200 {object} object{id=int,name=string}
+// @Failure 404 {object} object{message=string}
+// @Router /users/{id} [get]
+func GetUser(){
+}
+
+// @Success 200 {object} object{status=string}
+// @Router /health [get]
+func Health(){
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	parse := func() *spec.Swagger {
+		p := New()
+		p.InlineResponseName = OperationResponseName
+		assert.NoError(t, p.ParseRouterAPIInfo("", f))
+		return p.swagger
+	}
+
+	// the names don't change from one run to another
+	first, second := parse(), parse()
+	assert.Equal(t, first.Definitions, second.Definitions)
+	assert.Len(t, first.Definitions, 2)
+	assert.Contains(t, first.Definitions["getUser_200"].Properties, "name")
+	assert.Contains(t, first.Definitions["getUser_404"].Properties, "message")
+
+	responses := first.Paths.Paths["/users/{id}"].Get.Responses.StatusCodeResponses
+	assert.Equal(t, "#/definitions/getUser_200", responses[200].Schema.Ref.String())
+	assert.Equal(t, "#/definitions/getUser_404", responses[404].Schema.Ref.String())
+	// without operation id the response stays inline
+	health := first.Paths.Paths["/health"].Get.Responses.StatusCodeResponses[200].Schema
+	assert.Contains(t, health.Properties, "status")
+
+	p := New()
+	p.InlineResponseName = func(operationID, status string) string {
+		return "Inline"
+	}
+	err = p.ParseRouterAPIInfo("", f)
+	assert.EqualError(t, err, "ParseComment error in file  :name Inline of the inline 404 response is already taken")
+
+	p = New()
+	assert.NoError(t, p.ParseRouterAPIInfo("", f))
+	assert.Empty(t, p.swagger.Definitions)
+}
+
+func TestParser_ParseSummaryFromFuncName(t *testing.T) {
+	t.Parallel()
+
+	src := `
+package api
+
+// @Router /users/{id} [get]
+func GetUserByID(){
+}
+
+// @Summary List the users
+// @Router /users [get]
+func ListUsers(){
+}
+
+var handlers = map[string]func(){
+	// @Router /health [get]
+	"health": func(){},
+}
+`
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.ParseComments)
+	assert.NoError(t, err)
+
+	p := New()
+	p.SummaryFromFuncName = true
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Get user by ID", p.swagger.Paths.Paths["/users/{id}"].Get.Summary)
+	assert.Equal(t, "List the users", p.swagger.Paths.Paths["/users"].Get.Summary)
+	assert.Equal(t, "", p.swagger.Paths.Paths["/health"].Get.Summary)
+
+	p = New()
+	err = p.ParseRouterAPIInfo("", f)
+	assert.NoError(t, err)
+	assert.Equal(t, "", p.swagger.Paths.Paths["/users/{id}"].Get.Summary)
+
+	for funcName, expected := range map[string]string{
+		"GetUserByID":       "Get user by ID",
+		"getUsers":          "Get users",
+		"ParseHTTPRequest":  "Parse HTTP request",
+		"create_order_item": "Create order item",
+		"UploadV2File":      "Upload V2 file",
+		"A":                 "A",
+	} {
+		assert.Equal(t, expected, funcNameSummary(funcName), funcName)
+	}
+}
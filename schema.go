@@ -119,16 +119,8 @@ func TransToValidCollectionFormat(format string) string {
 // TypeDocName get alias from comment '// @name ', otherwise the original type name to display in doc
 func TypeDocName(pkgName string, spec *ast.TypeSpec) string {
 	if spec != nil {
-		if spec.Comment != nil {
-			for _, comment := range spec.Comment.List {
-				text := strings.TrimSpace(comment.Text)
-				text = strings.TrimLeft(text, "//")
-				text = strings.TrimSpace(text)
-				texts := strings.Split(text, " ")
-				if len(texts) > 1 && strings.ToLower(texts[0]) == "@name" {
-					return texts[1]
-				}
-			}
+		if name := nameAnnotation(spec.Comment); name != "" {
+			return name
 		}
 		if spec.Name != nil {
 			return fullTypeName(strings.Split(pkgName, ".")[0], spec.Name.Name)
@@ -138,6 +130,23 @@ func TypeDocName(pkgName string, spec *ast.TypeSpec) string {
 	return pkgName
 }
 
+// nameAnnotation returns the name of a '// @name ' annotation in comments, if any.
+func nameAnnotation(comments *ast.CommentGroup) string {
+	if comments == nil {
+		return ""
+	}
+	for _, comment := range comments.List {
+		text := strings.TrimSpace(comment.Text)
+		text = strings.TrimLeft(text, "//")
+		text = strings.TrimSpace(text)
+		texts := strings.Split(text, " ")
+		if len(texts) > 1 && strings.ToLower(texts[0]) == "@name" {
+			return texts[1]
+		}
+	}
+	return ""
+}
+
 //RefSchema build a reference schema
 func RefSchema(refType string) *spec.Schema {
 	return spec.RefSchema("#/definitions/" + refType)
@@ -148,6 +157,13 @@ func PrimitiveSchema(refType string) *spec.Schema {
 	return &spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{refType}}}
 }
 
+// formattedSchema build a primitive schema of a format
+func formattedSchema(refType, format string) *spec.Schema {
+	schema := PrimitiveSchema(refType)
+	schema.Format = format
+	return schema
+}
+
 // BuildCustomSchema build custom schema specified by tag swaggertype
 func BuildCustomSchema(types []string) (*spec.Schema, error) {
 	if len(types) == 0 {
@@ -183,6 +199,33 @@ func BuildCustomSchema(types []string) (*spec.Schema, error) {
 		if err != nil {
 			return nil, err
 		}
-		return PrimitiveSchema(types[0]), nil
+		schema := PrimitiveSchema(types[0])
+		switch len(types) {
+		case 1:
+		case 2:
+			// like integer,int64
+			schema.Format = types[1]
+		default:
+			return nil, fmt.Errorf("need only a format after %s", types[0])
+		}
+		return schema, nil
+	}
+}
+
+// EnumSchema returns a copy of schema with the values of enums as enum, and their names as x-enum-varnames
+// so clients can generate named constants.
+func EnumSchema(schema *spec.Schema, enums []EnumValue) *spec.Schema {
+	enumSchema := *schema
+	enumSchema.Enum = make([]interface{}, 0, len(enums))
+	varNames := make([]string, 0, len(enums))
+	for _, enum := range enums {
+		enumSchema.Enum = append(enumSchema.Enum, enum.Value)
+		varNames = append(varNames, enum.Name)
+	}
+	enumSchema.Extensions = spec.Extensions{}
+	for key, value := range schema.Extensions {
+		enumSchema.Extensions[key] = value
 	}
+	enumSchema.Extensions["x-enum-varnames"] = varNames
+	return &enumSchema
 }
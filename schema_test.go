@@ -92,6 +92,20 @@ func TestBuildCustomSchema(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, schema.SchemaProps.Type, spec.StringOrArray{"string"})
 
+	schema, err = BuildCustomSchema([]string{"integer", "int64"})
+	assert.NoError(t, err)
+	assert.Equal(t, schema.SchemaProps.Type, spec.StringOrArray{"integer"})
+	assert.Equal(t, "int64", schema.Format)
+
+	schema, err = BuildCustomSchema([]string{"primitive", "string", "date-time"})
+	assert.NoError(t, err)
+	assert.Equal(t, schema.SchemaProps.Type, spec.StringOrArray{"string"})
+	assert.Equal(t, "date-time", schema.Format)
+
+	schema, err = BuildCustomSchema([]string{"integer", "int64", "oops"})
+	assert.Error(t, err)
+	assert.Nil(t, schema)
+
 	schema, err = BuildCustomSchema([]string{"array"})
 	assert.Error(t, err)
 	assert.Nil(t, schema)
@@ -10,7 +10,7 @@ const Name = "swagger"
 
 var (
 	swaggerMu sync.RWMutex
-	swag      Swagger
+	swags     map[string]Swagger
 )
 
 // Swagger is a interface to read swagger document.
@@ -26,16 +26,37 @@ func Register(name string, swagger Swagger) {
 		panic("swagger is nil")
 	}
 
-	if swag != nil {
+	if swags == nil {
+		swags = make(map[string]Swagger)
+	}
+
+	if _, ok := swags[name]; ok {
 		panic("Register called twice for swag: " + name)
 	}
-	swag = swagger
+	swags[name] = swagger
 }
 
-// ReadDoc reads swagger document.
-func ReadDoc() (string, error) {
-	if swag != nil {
+// ReadDoc reads swagger document. An optional name reads the document registered
+// under that name, like one of several API versions; the default is Name, or the only
+// registered document.
+func ReadDoc(optionalName ...string) (string, error) {
+	swaggerMu.RLock()
+	defer swaggerMu.RUnlock()
+
+	if len(optionalName) > 0 && optionalName[0] != "" {
+		if swag, ok := swags[optionalName[0]]; ok {
+			return swag.ReadDoc(), nil
+		}
+		return "", errors.New("not yet registered swag")
+	}
+
+	if swag, ok := swags[Name]; ok {
 		return swag.ReadDoc(), nil
 	}
+	if len(swags) == 1 {
+		for _, swag := range swags {
+			return swag.ReadDoc(), nil
+		}
+	}
 	return "", errors.New("not yet registered swag")
 }
@@ -184,6 +184,37 @@ func TestCalledTwicelRegister(t *testing.T) {
 	})
 }
 
+func TestRegisterMultipleNames(t *testing.T) {
+	setup()
+	Register(Name, &s{})
+	Register("v2", &s{})
+
+	d, err := ReadDoc("v2")
+	assert.NoError(t, err)
+	assert.Equal(t, doc, d)
+
+	_, err = ReadDoc("v3")
+	assert.Error(t, err)
+}
+
+func TestReadDocOnlyRegistered(t *testing.T) {
+	setup()
+	_, err := ReadDoc()
+	assert.Error(t, err)
+
+	Register("v2", &s{})
+	d, err := ReadDoc()
+	assert.NoError(t, err)
+	assert.Equal(t, doc, d)
+
+	_, err = ReadDoc("v1")
+	assert.Error(t, err)
+
+	Register("v3", &s{})
+	_, err = ReadDoc()
+	assert.Error(t, err)
+}
+
 func setup() {
-	swag = nil
+	swags = nil
 }
@@ -0,0 +1,6 @@
+package main
+
+// GetStatus
+// @Success 200 {string} string
+// @Router /status [get]
+func GetStatus() {}
@@ -0,0 +1,9 @@
+//go:build !prod
+// +build !prod
+
+package main
+
+// @title Development API
+// @version 1.0
+// @host localhost:8080
+func main() {}
@@ -0,0 +1,9 @@
+//go:build prod
+// +build prod
+
+package main
+
+// @title Production API
+// @version 1.0
+// @host api.example.com
+func main() {}
@@ -0,0 +1,24 @@
+package api
+
+import "net/http"
+
+// ListPets lists all pets
+// @Summary List pets
+// @Success 200 {array} string
+// @Router /pets [get]
+func ListPets(w http.ResponseWriter, r *http.Request) {
+}
+
+// GetPet gets a pet by its id
+// @Summary Get a pet
+// @Param id path int true
+// @Router /pets/{id} [get]
+func GetPet(w http.ResponseWriter, r *http.Request) {
+}
+
+// ListOwners lists all owners
+// @Summary List owners
+// @Success 200 {object} api.Unknown
+// @Router /owners [get]
+func ListOwners(w http.ResponseWriter, r *http.Request) {
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Nerzal/swag/testdata/diagnostics/api"
+)
+
+// @title Swagger Example API
+// @version 1.0
+// @description This is a sample server with malformed annotations.
+func main() {
+	http.HandleFunc("/pets", api.ListPets)
+	http.HandleFunc("/pets/{id}", api.GetPet)
+	http.HandleFunc("/owners", api.ListOwners)
+	http.ListenAndServe(":8080", nil)
+}
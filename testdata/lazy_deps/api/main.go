@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/example/money"
+)
+
+// @title Lazy Dependencies API
+// @version 1.0
+func main() {}
+
+// GetPrice
+// @Success 200 {object} money.Price
+// @Router /price [get]
+func GetPrice() {}
+
+// GetTotal
+// @Success 200 {object} Total
+// @Router /total [get]
+func GetTotal() {}
+
+// Total sum of prices
+type Total struct {
+	Prices []money.Price `json:"prices"`
+	Sum    money.Price   `json:"sum"`
+}
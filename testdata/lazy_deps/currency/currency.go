@@ -0,0 +1,7 @@
+package currency
+
+// Currency ISO 4217 currency
+type Currency struct {
+	Code   string `json:"code"`
+	Symbol string `json:"symbol"`
+}
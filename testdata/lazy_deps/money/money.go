@@ -0,0 +1,9 @@
+package money
+
+import "github.com/example/currency"
+
+// Price amount in a currency
+type Price struct {
+	Amount   float64           `json:"amount"`
+	Currency currency.Currency `json:"currency"`
+}
@@ -0,0 +1,14 @@
+package base
+
+// Status of a record
+type Status string
+
+const (
+	Active  Status = "active"
+	Deleted Status = "deleted"
+)
+
+type Audit struct {
+	CreatedBy string `json:"createdBy"`
+	Status    Status `json:"status"`
+}
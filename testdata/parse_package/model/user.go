@@ -0,0 +1,17 @@
+package model
+
+import "github.com/Nerzal/swag/testdata/parse_package/base"
+
+type User struct {
+	ID    int        `json:"id"`
+	Audit base.Audit `json:"audit"`
+	Pets  []Pet      `json:"pets"`
+}
+
+type Pet struct {
+	Name string `json:"name"`
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
@@ -0,0 +1,10 @@
+package main
+
+// @title Platform API
+// @version 1.0
+func main() {}
+
+// GetProcess
+// @Success 200 {object} Process
+// @Router /process [get]
+func GetProcess() {}
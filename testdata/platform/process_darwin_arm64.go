@@ -0,0 +1,7 @@
+package main
+
+// Process a process of macOS on Apple silicon
+type Process struct {
+	PID        int  `json:"pid"`
+	Translated bool `json:"translated"`
+}
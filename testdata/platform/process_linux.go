@@ -0,0 +1,7 @@
+package main
+
+// Process a process of linux
+type Process struct {
+	PID    int    `json:"pid"`
+	Cgroup string `json:"cgroup"`
+}
@@ -0,0 +1,7 @@
+package main
+
+// Process a process of windows
+type Process struct {
+	PID    int    `json:"pid"`
+	Handle uint64 `json:"handle"`
+}
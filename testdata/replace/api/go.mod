@@ -0,0 +1,7 @@
+module github.com/example/api
+
+go 1.15
+
+require github.com/example/shared v0.0.0
+
+replace github.com/example/shared => ../shared
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/example/shared/model"
+)
+
+// @title Swagger Example API
+// @version 1.0
+func main() {}
+
+// GetUser
+// @Success 200 {object} model.User
+// @Router /users/{id} [get]
+func GetUser() model.User {
+	return model.User{}
+}
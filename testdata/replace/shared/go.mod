@@ -0,0 +1,3 @@
+module github.com/example/shared
+
+go 1.15
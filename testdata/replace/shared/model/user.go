@@ -0,0 +1,6 @@
+package model
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
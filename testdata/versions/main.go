@@ -0,0 +1,6 @@
+package main
+
+// @title Versions API
+// @version 1.0
+// @BasePath /api
+func main() {}
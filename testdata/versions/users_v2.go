@@ -0,0 +1,9 @@
+//go:build v2
+// +build v2
+
+package main
+
+// GetUsers
+// @Success 200 {array} string
+// @Router /v2/users [get]
+func GetUsers() {}
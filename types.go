@@ -1,8 +1,10 @@
 package swag
 
 import (
-	"github.com/go-openapi/spec"
 	"go/ast"
+	"strings"
+
+	"github.com/go-openapi/spec"
 )
 
 //Schema parsed schema
@@ -22,6 +24,30 @@ type TypeSpecDef struct {
 
 	//the TypeSpec of this type definition
 	TypeSpec *ast.TypeSpec
+
+	//the function a local type is declared in, nil for package level types
+	ParentSpec *ast.FuncDecl
+
+	//names of the type parameters of a generic type, e.g. [T] of Page[T any]
+	TypeParams []string
+
+	//full names of the type arguments of an instantiated generic type, e.g. [model.User] of Page[model.User]
+	TypeArgs []string
+
+	//type arguments bound to TypeParams, map key is the name of the type parameter
+	typeArgs map[string]*typeArg
+
+	//constants declared with this type, in the order of their declaration
+	Enums []EnumValue
+}
+
+//EnumValue a constant of a type, which is one of the values of its enum
+type EnumValue struct {
+	//Name the go identifier of the constant
+	Name string
+
+	//Value the value of the constant
+	Value interface{}
 }
 
 //Name name of the typeSpec
@@ -31,7 +57,21 @@ func (t *TypeSpecDef) Name() string {
 
 //FullName full name of the typeSpec
 func (t *TypeSpecDef) FullName() string {
-	return fullTypeName(t.File.Name.Name, t.TypeSpec.Name.Name)
+	typeName := t.TypeSpec.Name.Name
+	if t.ParentSpec != nil {
+		typeName = t.ParentSpec.Name.Name + "." + typeName
+	}
+	fullName := fullTypeName(t.File.Name.Name, typeName)
+	if len(t.TypeArgs) > 0 {
+		fullName += "[" + strings.Join(t.TypeArgs, ",") + "]"
+	}
+	return fullName
+}
+
+// pathName returns the full name of the type qualified by its package path, which tells apart the types of
+// packages sharing their name.
+func (t *TypeSpecDef) pathName() string {
+	return t.PkgPath + ":" + t.FullName()
 }
 
 //AstFileInfo information of a ast.File